@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +17,56 @@ type Config struct {
 	DBType     string
 	JWTSecret  string
 	ServerPort string
+
+	// 邮件服务（SMTP），SMTPHost 为空时不发送邮件
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// 考勤周报发送时间：星期几（0 为周日）和小时
+	DigestWeekday int
+	DigestHour    int
+
+	// 前端地址，用于生成邮件中的链接
+	FrontendURL string
+
+	// 可信反向代理的 IP 或网段，逗号分隔；为空时不信任 X-Forwarded-For，直接使用连接地址
+	TrustedProxies string
+
+	// 登录认证方式，逗号分隔，按顺序尝试：local（本地密码）、ldap
+	AuthProviders string
+
+	// LDAP 目录
+	LDAPURL          string
+	LDAPStartTLS     bool
+	LDAPBindDN       string // 用于查找用户的服务账号，为空时匿名查找
+	LDAPBindPassword string
+	LDAPBaseDN       string
+	LDAPUserFilter   string // 查找用户的过滤器，%s 替换为登录名
+	LDAPAttrUsername string
+	LDAPAttrName     string
+	LDAPAttrEmail    string
+	LDAPAttrRole     string
+	LDAPRoleMap      string // 目录属性值到系统角色的映射，如 faculty=teacher,student=student
+	LDAPDefaultRole  string // 无法映射角色时使用的角色，为空时拒绝登录
+	LDAPLinkLocal    bool   // 目录认证通过时是否接管同名的本地账号
+
+	// OpenID Connect 单点登录，OIDCIssuer 为空时不启用
+	OIDCIssuer        string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string // 身份提供方回调地址，指向本服务的 /api/oidc/callback
+	OIDCScopes        string // 空格分隔
+	OIDCDisplayName   string // 登录页按钮上显示的名称
+	OIDCUsernameClaim string
+	OIDCNameClaim     string
+	OIDCEmailClaim    string
+	OIDCRoleClaim     string
+	OIDCRoleMap       string // 声明值到系统角色的映射，如 teachers=teacher,students=student
+	OIDCDefaultRole   string // 无法映射角色时使用的角色，为空时拒绝开通新用户
+	OIDCLinkUsername  bool   // 是否按用户名声明关联已有账号，默认只按已验证的邮箱关联
 }
 
 var Cfg *Config
@@ -34,6 +85,49 @@ func LoadConfig() {
 		DBType:     getEnv("DB_TYPE", "mysql"),
 		JWTSecret:  getEnv("JWT_SECRET", "secret"),
 		ServerPort: getEnv("SERVER_PORT", "8080"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "25"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
+
+		DigestWeekday: getEnvInt("DIGEST_WEEKDAY", 1),
+		DigestHour:    getEnvInt("DIGEST_HOUR", 8),
+
+		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+
+		AuthProviders: getEnv("AUTH_PROVIDERS", "local"),
+
+		LDAPURL:          getEnv("LDAP_URL", ""),
+		LDAPStartTLS:     getEnvBool("LDAP_START_TLS", false),
+		LDAPBindDN:       getEnv("LDAP_BIND_DN", ""),
+		LDAPBindPassword: getEnv("LDAP_BIND_PASSWORD", ""),
+		LDAPBaseDN:       getEnv("LDAP_BASE_DN", ""),
+		LDAPUserFilter:   getEnv("LDAP_USER_FILTER", "(uid=%s)"),
+		LDAPAttrUsername: getEnv("LDAP_ATTR_USERNAME", "uid"),
+		LDAPAttrName:     getEnv("LDAP_ATTR_NAME", "cn"),
+		LDAPAttrEmail:    getEnv("LDAP_ATTR_EMAIL", "mail"),
+		LDAPAttrRole:     getEnv("LDAP_ATTR_ROLE", "eduPersonAffiliation"),
+		LDAPRoleMap:      getEnv("LDAP_ROLE_MAP", "faculty=teacher,staff=assistant,student=student"),
+		LDAPDefaultRole:  getEnv("LDAP_DEFAULT_ROLE", ""),
+		LDAPLinkLocal:    getEnvBool("LDAP_LINK_LOCAL", false),
+
+		OIDCIssuer:        getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/oidc/callback"),
+		OIDCScopes:        getEnv("OIDC_SCOPES", "openid profile email"),
+		OIDCDisplayName:   getEnv("OIDC_DISPLAY_NAME", "统一身份认证"),
+		OIDCUsernameClaim: getEnv("OIDC_USERNAME_CLAIM", "preferred_username"),
+		OIDCNameClaim:     getEnv("OIDC_NAME_CLAIM", "name"),
+		OIDCEmailClaim:    getEnv("OIDC_EMAIL_CLAIM", "email"),
+		OIDCRoleClaim:     getEnv("OIDC_ROLE_CLAIM", "groups"),
+		OIDCRoleMap:       getEnv("OIDC_ROLE_MAP", "teachers=teacher,assistants=assistant,students=student"),
+		OIDCDefaultRole:   getEnv("OIDC_DEFAULT_ROLE", ""),
+		OIDCLinkUsername:  getEnvBool("OIDC_LINK_USERNAME", false),
 	}
 }
 
@@ -44,6 +138,26 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvInt(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+		log.Printf("环境变量 %s 不是有效的整数，使用默认值 %d", key, fallback)
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		log.Printf("环境变量 %s 不是有效的布尔值，使用默认值 %t", key, fallback)
+	}
+	return fallback
+}
+
 // DB_DSN 生成 MySQL DSN
 func (c *Config) DB_DSN() string {
 	return c.DBUser + ":" + c.DBPassword + "@tcp(" + c.DBHost + ":" + c.DBPort + ")/" + c.DBName + "?charset=utf8mb4&parseTime=True&loc=Local"
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var analyticsService = &services.AnalyticsService{}
+
+// analyticsDimensionLabels 分组维度的中文列名，用于 CSV 导出
+var analyticsDimensionLabels = map[string]string{
+	"semester":  "学期",
+	"teacher":   "教师",
+	"course":    "课程",
+	"weekday":   "星期",
+	"time_slot": "时段",
+}
+
+// GetAttendanceAnalytics 管理员全校考勤分析
+// 支持 semester、teacher_id、course_id、start_date、end_date 过滤，group_by 为逗号分隔的维度，format=csv 时下载 CSV
+func GetAttendanceAnalytics(c *gin.Context) {
+	dimensions, err := services.ParseAnalyticsDimensions(c.Query("group_by"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := services.AnalyticsFilter{Semester: c.Query("semester")}
+	if filter.DateRange, err = parseDateRange(c); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if teacherIDStr := c.Query("teacher_id"); teacherIDStr != "" {
+		id, err := strconv.ParseUint(teacherIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的教师ID")
+			return
+		}
+		filter.TeacherID = uint(id)
+	}
+	if courseIDStr := c.Query("course_id"); courseIDStr != "" {
+		id, err := strconv.ParseUint(courseIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的课程ID")
+			return
+		}
+		filter.CourseID = uint(id)
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		response.Error(c, http.StatusBadRequest, "不支持的导出格式: "+format)
+		return
+	}
+
+	result, err := analyticsService.Aggregate(filter, dimensions)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取考勤分析失败: "+err.Error())
+		return
+	}
+
+	if format == "json" {
+		response.Success(c, result)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	setAttachment(c, "attendance_analytics.csv")
+	c.Status(http.StatusOK)
+
+	c.Writer.WriteString(utf8BOM)
+	writer := csv.NewWriter(c.Writer)
+	header := make([]string, 0, len(dimensions)+7)
+	for _, dimension := range dimensions {
+		header = append(header, analyticsDimensionLabels[dimension])
+	}
+	header = append(header, "签到次数", "应到人次", "出勤", "迟到", "缺勤", "请假", "出勤率")
+	writer.Write(header)
+	for _, row := range result.Rows {
+		line := make([]string, 0, len(header))
+		for _, dimension := range dimensions {
+			line = append(line, services.SanitizeCell(row.Keys[dimension]))
+		}
+		line = append(line,
+			strconv.Itoa(row.Sessions),
+			strconv.Itoa(row.Expected),
+			strconv.Itoa(row.Present),
+			strconv.Itoa(row.Late),
+			strconv.Itoa(row.Absent),
+			strconv.Itoa(row.Excused),
+			fmt.Sprintf("%.2f%%", row.AttendanceRate*100),
+		)
+		writer.Write(line)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	models "backend/internal/model"
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var attendanceRequestService = &services.AttendanceRequestService{}
+
+func attendanceRequestResponse(request models.AttendanceRequest) gin.H {
+	var reviewedAt interface{}
+	if request.ReviewedAt != nil {
+		reviewedAt = request.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+	return gin.H{
+		"id":                 request.ID,
+		"type":               request.Type,
+		"student_id":         request.StudentID,
+		"student_name":       request.Student.Name,
+		"course_id":          request.CourseID,
+		"course_name":        request.Course.Name,
+		"session_id":         request.SessionID,
+		"session_start_time": request.Session.StartTime.Format("2006-01-02 15:04:05"),
+		"reason":             request.Reason,
+		"status":             request.Status,
+		"review_comment":     request.ReviewComment,
+		"reviewed_at":        reviewedAt,
+		"created_at":         request.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// GetAttendanceRequests 获取待当前教师/助教审核的请假与申诉，默认只返回待审核的申请
+func GetAttendanceRequests(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	status := c.DefaultQuery("status", "pending")
+	if status == "all" {
+		status = ""
+	}
+
+	requests, err := attendanceRequestService.GetReviewableRequests(userID, role, status)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取申请列表失败")
+		return
+	}
+
+	result := make([]gin.H, 0, len(requests))
+	for _, request := range requests {
+		result = append(result, attendanceRequestResponse(request))
+	}
+	response.Success(c, result)
+}
+
+// ReviewAttendanceRequest 审核请假或申诉
+func ReviewAttendanceRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的申请ID")
+		return
+	}
+
+	var req struct {
+		Approve *bool  `json:"approve" binding:"required"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	request, err := attendanceRequestService.GetRequestByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if _, ok := authorizeCourse(c, request.CourseID); !ok {
+		return
+	}
+
+	request, err = attendanceRequestService.ReviewRequest(request.ID, userID, *req.Approve, req.Comment)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"id":      request.ID,
+		"status":  request.Status,
+		"message": "审核完成",
+	})
+}
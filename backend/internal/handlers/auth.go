@@ -2,14 +2,22 @@ package handlers
 
 import (
 	models "backend/internal/model"
-	"backend/pkg/database"
+	"backend/internal/services"
 	"backend/pkg/response"
 	"backend/pkg/utils"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	authService          = &services.AuthService{}
+	loginThrottleService = &services.LoginThrottleService{}
+)
+
+// Login 用户名密码登录，返回访问令牌和刷新令牌
 func Login(c *gin.Context) {
 	var req struct {
 		Username string `json:"username" binding:"required"`
@@ -21,30 +29,176 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := database.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		response.Error(c, http.StatusUnauthorized, "用户名或密码错误")
+	// 账号或 IP 连续失败次数过多时拒绝登录
+	clientIP := c.ClientIP()
+	if !checkLoginAllowed(c, req.Username, clientIP) {
 		return
 	}
 
-	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
-		response.Error(c, http.StatusUnauthorized, "用户名或密码错误")
+	// 依次尝试本地密码、LDAP 等认证方式，目录用户首次登录时自动开通
+	user, err := authService.Authenticate(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			loginThrottleService.RecordFailure(req.Username, clientIP)
+			response.Error(c, http.StatusUnauthorized, "用户名或密码错误")
+			return
+		}
+		log.Printf("用户 %s 登录认证失败: %v", req.Username, err)
+		response.Error(c, http.StatusServiceUnavailable, "认证服务暂不可用，请稍后再试")
 		return
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Role)
+	finishLogin(c, user, req.Username)
+}
+
+// finishLogin 身份校验通过后完成登录：需要双因素认证时返回第二步令牌，否则清除失败计数并签发令牌
+func finishLogin(c *gin.Context, user *models.User, username string) {
+	// 启用了双因素认证或所在角色强制要求时，密码正确后还需完成第二步
+	// 此时不清除失败计数，第二步的失败同样计入，避免验证码被暴力猜测
+	enabled, err := twoFactorService.IsEnabled(user.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "登录失败")
+		return
+	}
+	if enabled {
+		respondTwoFactorChallenge(c, user.ID, services.PurposeTwoFactorLogin, "two_factor_required")
+		return
+	}
+	required, err := twoFactorService.IsRequired(user.Role)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "登录失败")
+		return
+	}
+	if required {
+		respondTwoFactorChallenge(c, user.ID, services.PurposeTwoFactorSetup, "two_factor_setup_required")
+		return
+	}
+
+	loginThrottleService.RecordSuccess(username)
+	respondLoginTokens(c, user, nil)
+}
+
+// checkLoginAllowed 账号或 IP 处于锁定状态时写入错误响应并返回 false
+func checkLoginAllowed(c *gin.Context, username, clientIP string) bool {
+	if err := loginThrottleService.CheckAllowed(username, clientIP); err != nil {
+		var lockedErr *services.LockedError
+		if errors.As(err, &lockedErr) {
+			response.Error(c, http.StatusTooManyRequests, lockedErr.Error())
+			return false
+		}
+		response.Error(c, http.StatusInternalServerError, "登录失败")
+		return false
+	}
+	return true
+}
+
+// respondLoginTokens 签发令牌并返回登录结果，extra 中的字段一并返回
+// 用户必须修改初始密码时只返回修改密码的专用令牌，改密后再签发正式令牌
+func respondLoginTokens(c *gin.Context, user *models.User, extra gin.H) {
+	userInfo := gin.H{
+		"id":                   user.ID,
+		"username":             user.Username,
+		"name":                 user.Name,
+		"role":                 user.Role,
+		"must_change_password": user.MustChangePassword,
+	}
+
+	if user.MustChangePassword {
+		changeToken, err := utils.GeneratePurposeToken(user.ID, services.PurposePasswordChange, services.PasswordChangeTTL)
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "生成 Token 失败")
+			return
+		}
+		data := gin.H{
+			"must_change_password":  true,
+			"password_change_token": changeToken,
+			"expires_in":            int(services.PasswordChangeTTL.Seconds()),
+			"user":                  userInfo,
+		}
+		for key, value := range extra {
+			data[key] = value
+		}
+		response.Success(c, data)
+		return
+	}
+
+	tokens, err := authService.IssueTokens(user)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "生成 Token 失败")
 		return
 	}
 
+	data := gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          userInfo,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	response.Success(c, data)
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌
+func RefreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "刷新令牌不能为空")
+		return
+	}
+
+	tokens, _, err := authService.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			response.Error(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "刷新 Token 失败")
+		return
+	}
+
 	response.Success(c, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"name":     user.Name,
-			"role":     user.Role,
-		},
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
+
+// Logout 退出登录，吊销当前设备的刷新令牌
+func Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "刷新令牌不能为空")
+		return
+	}
+
+	if err := authService.Logout(req.RefreshToken); err != nil {
+		response.Error(c, http.StatusInternalServerError, "退出登录失败")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已退出登录"})
+}
+
+// LogoutAll 退出所有设备，吊销当前用户已签发的全部令牌
+func LogoutAll(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := authService.RevokeUserTokens(userID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "退出登录失败")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已退出所有设备"})
+}
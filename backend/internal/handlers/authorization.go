@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	models "backend/internal/model"
+	"backend/internal/services"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var authorizationService = &services.AuthorizationService{}
+
+// respondAuthorizationError 将资源归属校验的错误写入响应
+func respondAuthorizationError(c *gin.Context, err error, notFoundMsg string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, notFoundMsg)
+	case errors.Is(err, services.ErrForbidden):
+		response.Error(c, http.StatusForbidden, "您无权操作该资源")
+	default:
+		response.Error(c, http.StatusInternalServerError, "权限校验失败")
+	}
+}
+
+// authorizeCourse 校验当前用户可以访问课程的签到数据，失败时写入错误响应并返回 false
+func authorizeCourse(c *gin.Context, courseID uint) (*models.Course, bool) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return nil, false
+	}
+
+	course, err := authorizationService.AuthorizeCourse(userID, role, courseID)
+	if err != nil {
+		respondAuthorizationError(c, err, "课程不存在")
+		return nil, false
+	}
+	return course, true
+}
+
+// authorizeCourseOwner 校验当前用户是课程教师或管理员，失败时写入错误响应并返回 false
+func authorizeCourseOwner(c *gin.Context, courseID uint) (*models.Course, bool) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return nil, false
+	}
+
+	course, err := authorizationService.AuthorizeCourseOwner(userID, role, courseID)
+	if err != nil {
+		respondAuthorizationError(c, err, "课程不存在")
+		return nil, false
+	}
+	return course, true
+}
+
+// authorizeSession 校验当前用户可以操作签到会话，失败时写入错误响应并返回 false
+func authorizeSession(c *gin.Context, sessionID uint) (*models.CheckinSession, bool) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return nil, false
+	}
+
+	session, err := authorizationService.AuthorizeSession(userID, role, sessionID)
+	if err != nil {
+		respondAuthorizationError(c, err, "签到会话不存在")
+		return nil, false
+	}
+	return session, true
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var calendarService = &services.CalendarService{}
+
+// calendarFeedURL 订阅地址（相对路径），由前端拼接服务器地址
+func calendarFeedURL(token string) string {
+	return "/api/calendar/" + token + ".ics"
+}
+
+// GetCalendarToken 获取当前用户的日历订阅地址
+func GetCalendarToken(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	token, err := calendarService.GetOrCreateToken(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取日历订阅地址失败")
+		return
+	}
+
+	response.Success(c, gin.H{"token": token, "url": calendarFeedURL(token)})
+}
+
+// ResetCalendarToken 重置当前用户的日历订阅地址
+func ResetCalendarToken(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	token, err := calendarService.ResetToken(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "重置日历订阅地址失败")
+		return
+	}
+
+	response.Success(c, gin.H{"token": token, "url": calendarFeedURL(token)})
+}
+
+// GetCalendarFeed 凭订阅令牌获取 .ics 日历（无需登录，供手机日历订阅）
+func GetCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	if token == "" {
+		response.Error(c, http.StatusNotFound, "日历不存在")
+		return
+	}
+
+	user, err := calendarService.GetUserByToken(token)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "日历不存在")
+		return
+	}
+
+	cal, err := calendarService.BuildCalendar(user)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成日历失败")
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="checkin.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", cal.Encode(time.Now()))
+}
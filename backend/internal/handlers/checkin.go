@@ -26,21 +26,12 @@ func StartCheckin(c *gin.Context) {
 		return
 	}
 
-	teacherIDFloat, exists := c.Get("user_id")
-	if !exists {
-		response.Error(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-	var teacherID uint
-	switch v := teacherIDFloat.(type) {
-	case float64:
-		teacherID = uint(v)
-	case uint:
-		teacherID = v
-	default:
-		response.Error(c, http.StatusInternalServerError, "无效的用户ID类型")
+	// 只能为本人任课（助教为被分配）的课程发起签到，会话归属于课程教师
+	course, ok := authorizeCourse(c, req.CourseID)
+	if !ok {
 		return
 	}
+	teacherID := course.TeacherID
 
 	sessionCode, err := services.CreateCheckinSession(teacherID, req.CourseID, req.Duration)
 	if err != nil {
@@ -83,7 +74,7 @@ func StudentCheckin(c *gin.Context) {
 		return
 	}
 
-	err := services.ProcessStudentCheckin(req.SessionCode, req.StudentID)
+	err := services.ProcessStudentCheckin(req.SessionCode, req.StudentID, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		// 使用 200 状态码返回业务错误，便于前端处理
 		response.Error(c, http.StatusOK, err.Error())
@@ -122,6 +113,10 @@ func GetCheckinRecords(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizeSession(c, uint(sessionID)); !ok {
+		return
+	}
+
 	records, err := services.GetCheckinRecordsBySession(uint(sessionID))
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取记录失败")
@@ -131,9 +126,29 @@ func GetCheckinRecords(c *gin.Context) {
 	response.Success(c, records)
 }
 
-// GetCheckinSessions 获取签到会话列表
+// checkinSessionListSpec 签到会话列表支持的排序和筛选参数，Course 为关联课程表别名
+var checkinSessionListSpec = services.ListSpec{
+	IDColumn: "checkin_sessions.id",
+	Sorts: map[string]string{
+		"id":          "checkin_sessions.id",
+		"start_time":  "checkin_sessions.start_time",
+		"status":      "checkin_sessions.status",
+		"course_name": "Course.name",
+	},
+	Filters: map[string]string{
+		"course_id": "checkin_sessions.course_id = ?",
+		"status":    "checkin_sessions.status = ?",
+		"semester":  "Course.semester = ?",
+	},
+	KeywordColumns: []string{"checkin_sessions.session_code", "Course.name"},
+}
+
+// GetCheckinSessions 获取签到会话列表，支持分页、排序、按课程/状态/学期筛选和关键字搜索
 func GetCheckinSessions(c *gin.Context) {
-	var sessions []models.CheckinSession
+	q, ok := bindListQuery(c, checkinSessionListSpec)
+	if !ok {
+		return
+	}
 	
 	// 获取当前用户信息
 	userIDFloat, exists := c.Get("user_id")
@@ -160,24 +175,30 @@ func GetCheckinSessions(c *gin.Context) {
 		return
 	}
 	
-	// 构建查询
-	query := database.DB.Preload("Course").Preload("Teacher")
+	// 构建查询，关联课程以便按学期和课程名称筛选
+	query := database.DB.Model(&models.CheckinSession{}).Joins("Course")
 	
-	// 如果是教师角色，只获取该教师创建的会话
-	if userRole == "teacher" {
-		query = query.Where("teacher_id = ?", userID)
-	}
+	// 教师只获取本人创建的会话，助教只获取被分配课程的会话
 	// 管理员可以看到所有会话，不需要额外过滤
+	roleStr, _ := userRole.(string)
+	query = services.ScopeSessionsByRole(query, userID, roleStr)
 	
-	result := query.Find(&sessions)
-	if result.Error != nil {
+	query, total, err := q.Apply(query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取签到会话列表失败")
+		return
+	}
+	var sessions []models.CheckinSession
+	if err := query.Preload("Teacher").Find(&sessions).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取签到会话列表失败")
 		return
 	}
 	
 	// 转换为前端需要的格式
 	sessionList := make([]gin.H, 0)
+	var lastID uint
 	for _, session := range sessions {
+		lastID = session.ID
 		sessionList = append(sessionList, gin.H{
 			"id":          session.ID,
 			"sessionCode": session.SessionCode,
@@ -189,7 +210,7 @@ func GetCheckinSessions(c *gin.Context) {
 		})
 	}
 	
-	response.Success(c, sessionList)
+	respondList(c, q, sessionList, total, len(sessions), lastID)
 }
 
 // EndCheckinSession 结束签到会话
@@ -201,6 +222,10 @@ func EndCheckinSession(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizeSession(c, uint(sessionID)); !ok {
+		return
+	}
+
 	err = services.EndCheckinSession(uint(sessionID))
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
@@ -229,6 +254,10 @@ func ManualCheckin(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizeSession(c, uint(sessionID)); !ok {
+		return
+	}
+
 	err = services.ManualCheckin(uint(sessionID), req.StudentID, req.Status)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
@@ -247,28 +276,15 @@ func ManualEndCheckinSession(c *gin.Context) {
 		return
 	}
 
-	teacherIDFloat, exists := c.Get("user_id")
-	if !exists {
-		response.Error(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-	
-	var teacherID uint
-	switch v := teacherIDFloat.(type) {
-	case float64:
-		teacherID = uint(v)
-	case uint:
-		teacherID = v
-	default:
-		response.Error(c, http.StatusInternalServerError, "无效的用户ID类型")
+	if _, ok := authorizeSession(c, uint(sessionID)); !ok {
 		return
 	}
 
-	err = services.ManualEndCheckinSession(uint(sessionID), teacherID)
+	err = services.ManualEndCheckinSession(uint(sessionID))
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response.Success(c, gin.H{"message": "签到会话已手动结束"})
-}
\ No newline at end of file
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var detectionService = &services.DetectionService{}
+
+// GetCheckinFlags 获取疑似代签的复核队列，默认只返回待复核的标记
+func GetCheckinFlags(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	status := c.DefaultQuery("status", "pending")
+	if status == "all" {
+		status = ""
+	}
+
+	var sessionID uint64
+	if sessionIDStr := c.Query("session_id"); sessionIDStr != "" {
+		var err error
+		sessionID, err = strconv.ParseUint(sessionIDStr, 10, 32)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的会话ID")
+			return
+		}
+	}
+
+	flags, err := detectionService.GetFlags(userID, role, status, uint(sessionID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取异常签到列表失败")
+		return
+	}
+
+	result := make([]gin.H, 0, len(flags))
+	for _, flag := range flags {
+		var reasons []services.FlagReason
+		json.Unmarshal([]byte(flag.Reasons), &reasons)
+
+		result = append(result, gin.H{
+			"id":             flag.ID,
+			"record_id":      flag.RecordID,
+			"session_id":     flag.SessionID,
+			"course_id":      flag.CourseID,
+			"student_id":     flag.StudentID,
+			"username":       flag.Student.Username,
+			"student_name":   flag.Student.Name,
+			"checkin_time":   flag.Record.CheckinTime.Format("2006-01-02 15:04:05"),
+			"client_ip":      flag.Record.ClientIP,
+			"user_agent":     flag.Record.UserAgent,
+			"score":          flag.Score,
+			"reasons":        reasons,
+			"status":         flag.Status,
+			"review_comment": flag.ReviewComment,
+		})
+	}
+
+	response.Success(c, result)
+}
+
+// ReviewCheckinFlag 复核疑似代签：confirm 确认（签到记录改为缺勤），clear 解除
+func ReviewCheckinFlag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的标记ID")
+		return
+	}
+
+	var req struct {
+		Action  string `json:"action" binding:"required,oneof=confirm clear"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	flag, err := detectionService.GetFlagByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if _, ok := authorizeCourse(c, flag.CourseID); !ok {
+		return
+	}
+
+	flag, err = detectionService.ReviewFlag(flag.ID, userID, req.Action, req.Comment)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"id":      flag.ID,
+		"status":  flag.Status,
+		"message": "复核完成",
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser 从上下文中获取当前登录用户的ID和角色（由 JWTAuth 中间件写入）
+func currentUser(c *gin.Context) (uint, string, bool) {
+	userIDValue, exists := c.Get("user_id")
+	if !exists {
+		return 0, "", false
+	}
+
+	var userID uint
+	switch v := userIDValue.(type) {
+	case float64:
+		userID = uint(v)
+	case uint:
+		userID = v
+	default:
+		return 0, "", false
+	}
+
+	role, _ := c.Get("role")
+	roleStr, _ := role.(string)
+	return userID, roleStr, true
+}
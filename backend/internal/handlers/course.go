@@ -18,11 +18,17 @@ type CourseHandler struct {
 	service services.CourseService
 }
 
-// GetCourses 获取所有课程
+var courseService = &services.CourseService{}
+
+// GetCourses 获取课程列表，支持分页、排序、按学期和教师筛选及关键字搜索
 func GetCourses(c *gin.Context) {
-	var courses []model.Course
-	result := database.DB.Preload("Teacher").Find(&courses)
-	if result.Error != nil {
+	q, ok := bindListQuery(c, services.CourseListSpec)
+	if !ok {
+		return
+	}
+
+	courses, total, err := courseService.ListCourses(q)
+	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取课程列表失败")
 		return
 	}
@@ -40,7 +46,7 @@ func GetCourses(c *gin.Context) {
 		UpdatedAt  string `json:"UpdatedAt"`
 	}
 
-	var responseCourses []courseResponse
+	responseCourses := make([]courseResponse, 0, len(courses))
 	for _, course := range courses {
 		// 处理教师信息可能为空的情况
 		teacherName := ""
@@ -61,7 +67,11 @@ func GetCourses(c *gin.Context) {
 		})
 	}
 
-	response.Success(c, responseCourses)
+	var lastID uint
+	if len(courses) > 0 {
+		lastID = courses[len(courses)-1].ID
+	}
+	respondList(c, q, responseCourses, total, len(courses), lastID)
 }
 
 // GetCourseByID 根据ID获取课程详情
@@ -120,8 +130,14 @@ func GetMyCourses(c *gin.Context) {
 	}
 
 	var courses []model.Course
-	result := database.DB.Preload("Teacher").Where("teacher_id = ?", userID).Find(&courses)
-	if result.Error != nil {
+	var err error
+	// 助教获取被分配的课程，教师获取自己负责的课程
+	if role, _ := c.Get("role"); role == "assistant" {
+		courses, err = courseService.GetCoursesByAssistantID(userID.(uint))
+	} else {
+		err = database.DB.Preload("Teacher").Where("teacher_id = ?", userID).Find(&courses).Error
+	}
+	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取我的课程失败")
 		return
 	}
@@ -171,6 +187,11 @@ func CreateCourse(c *gin.Context) {
 		return
 	}
 
+	if role, _ := c.Get("role"); role == "assistant" {
+		response.Error(c, http.StatusForbidden, "助教无权创建课程")
+		return
+	}
+
 	// 修改结构体定义，支持接收大驼峰命名的字段
 	var req struct {
 		CourseCode string `json:"CourseCode" binding:"required"`
@@ -192,11 +213,15 @@ func CreateCourse(c *gin.Context) {
 		return
 	}
 
-	// 如果没有提供教师ID，则使用当前用户ID
+	// 如果没有提供教师ID，则使用当前用户ID；只有管理员可以为其他教师创建课程
 	teacherID := req.TeacherID
 	if teacherID == 0 {
 		teacherID = userID.(uint)
 	}
+	if role, _ := c.Get("role"); role != "admin" && teacherID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "只能创建本人任课的课程")
+		return
+	}
 
 	// 创建课程对象
 	course := model.Course{
@@ -230,6 +255,11 @@ func UpdateCourse(c *gin.Context) {
 		return
 	}
 
+	if role, _ := c.Get("role"); role == "assistant" {
+		response.Error(c, http.StatusForbidden, "助教无权修改课程")
+		return
+	}
+
 	// 修改结构体定义，支持接收大驼峰命名的字段
 	var req struct {
 		CourseCode string `json:"CourseCode"`
@@ -244,15 +274,13 @@ func UpdateCourse(c *gin.Context) {
 		return
 	}
 
-	// 查找课程
-	var course model.Course
-	result := database.DB.First(&course, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			response.Error(c, http.StatusNotFound, "课程不存在")
-			return
-		}
-		response.Error(c, http.StatusInternalServerError, "查询课程失败")
+	// 查找课程并校验归属，只有课程教师和管理员可以修改
+	course, ok := authorizeCourseOwner(c, uint(id))
+	if !ok {
+		return
+	}
+	if role, _ := c.Get("role"); role != "admin" && req.TeacherID != 0 && req.TeacherID != course.TeacherID {
+		response.Error(c, http.StatusForbidden, "只有管理员可以更换课程教师")
 		return
 	}
 
@@ -276,7 +304,7 @@ func UpdateCourse(c *gin.Context) {
 	updates["updated_at"] = time.Now()
 
 	// 更新课程信息
-	result = database.DB.Model(&course).Updates(updates)
+	result := database.DB.Model(course).Updates(updates)
 	if result.Error != nil {
 		// 检查是否是唯一性约束错误
 		if strings.Contains(result.Error.Error(), "Duplicate entry") {
@@ -298,19 +326,19 @@ func DeleteCourse(c *gin.Context) {
 		return
 	}
 
-	var course model.Course
-	result := database.DB.First(&course, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			response.Error(c, http.StatusNotFound, "课程不存在")
-			return
-		}
-		response.Error(c, http.StatusInternalServerError, "查询课程失败")
+	if role, _ := c.Get("role"); role == "assistant" {
+		response.Error(c, http.StatusForbidden, "助教无权删除课程")
+		return
+	}
+
+	// 查找课程并校验归属，只有课程教师和管理员可以删除
+	course, ok := authorizeCourseOwner(c, uint(id))
+	if !ok {
 		return
 	}
 
 	// 删除课程
-	result = database.DB.Delete(&course)
+	result := database.DB.Delete(course)
 	if result.Error != nil {
 		response.Error(c, http.StatusInternalServerError, "删除课程失败: "+result.Error.Error())
 		return
@@ -318,3 +346,97 @@ func DeleteCourse(c *gin.Context) {
 
 	response.Success(c, gin.H{"message": "课程删除成功"})
 }
+
+// GetCourseAssistants 获取课程的助教列表
+func GetCourseAssistants(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的课程ID")
+		return
+	}
+
+	if _, ok := authorizeCourse(c, uint(id)); !ok {
+		return
+	}
+
+	assistants, err := courseService.GetAssistants(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取助教列表失败")
+		return
+	}
+
+	result := make([]gin.H, 0, len(assistants))
+	for _, assistant := range assistants {
+		result = append(result, gin.H{
+			"id":           assistant.ID,
+			"courseId":     assistant.CourseID,
+			"assistantId":  assistant.AssistantID,
+			"username":     assistant.Assistant.Username,
+			"name":         assistant.Assistant.Name,
+			"assignedTime": assistant.CreatedAt,
+		})
+	}
+
+	response.Success(c, result)
+}
+
+// AddCourseAssistant 为课程分配助教
+func AddCourseAssistant(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的课程ID")
+		return
+	}
+
+	var req struct {
+		AssistantID uint `json:"assistantId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	if _, ok := authorizeCourseOwner(c, uint(id)); !ok {
+		return
+	}
+
+	assistant, err := courseService.AssignAssistant(uint(id), req.AssistantID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"id":           assistant.ID,
+		"courseId":     assistant.CourseID,
+		"assistantId":  assistant.AssistantID,
+		"username":     assistant.Assistant.Username,
+		"name":         assistant.Assistant.Name,
+		"assignedTime": assistant.CreatedAt,
+	})
+}
+
+// RemoveCourseAssistant 取消课程的助教分配
+func RemoveCourseAssistant(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的课程ID")
+		return
+	}
+	assistantID, err := strconv.ParseUint(c.Param("assistant_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的助教ID")
+		return
+	}
+
+	if _, ok := authorizeCourseOwner(c, uint(id)); !ok {
+		return
+	}
+
+	if err := courseService.RemoveAssistant(uint(id), uint(assistantID)); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "助教已移除"})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var dashboardService = &services.DashboardService{}
+
+// GetTeacherDashboard 获取教师首页看板：今日签到、进行中签到、近期出勤率最低的课程和待审核申请
+func GetTeacherDashboard(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+	if role == "student" {
+		response.Error(c, http.StatusForbidden, "权限不足")
+		return
+	}
+
+	dashboard, err := dashboardService.GetTeacherDashboard(userID, role)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取看板数据失败: "+err.Error())
+		return
+	}
+
+	pendingRequests := make([]gin.H, 0, len(dashboard.PendingRequests))
+	for _, request := range dashboard.PendingRequests {
+		pendingRequests = append(pendingRequests, attendanceRequestResponse(request))
+	}
+
+	response.Success(c, gin.H{
+		"today_sessions":         dashboard.TodaySessions,
+		"active_sessions":        dashboard.ActiveSessions,
+		"low_attendance_courses": dashboard.LowAttendance,
+		"pending_request_count":  dashboard.PendingRequestCount,
+		"pending_requests":       pendingRequests,
+	})
+}
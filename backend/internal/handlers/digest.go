@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var digestService = &services.DigestService{}
+
+// SendWeeklyDigests 管理员手动触发发送考勤周报，统计截至今天的最近一周
+func SendWeeklyDigests(c *gin.Context) {
+	sent, err := digestService.SendWeeklyDigests(time.Now())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "发送考勤周报失败")
+		return
+	}
+
+	response.Success(c, gin.H{"sent": sent})
+}
+
+// GetEmailLogs 管理员查看邮件发送记录，支持 kind 过滤和 limit 限制条数（默认 100）
+func GetEmailLogs(c *gin.Context) {
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 1000 {
+			response.Error(c, http.StatusBadRequest, "无效的 limit 参数")
+			return
+		}
+		limit = l
+	}
+
+	logs, err := digestService.GetEmailLogs(c.Query("kind"), limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取邮件发送记录失败")
+		return
+	}
+
+	response.Success(c, logs)
+}
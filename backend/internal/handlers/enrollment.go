@@ -2,48 +2,80 @@ package handlers
 
 import (
 	models "backend/internal/model"
+	"backend/internal/services"
 	"backend/pkg/database"
 	"backend/pkg/response"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// GetEnrollments 获取所有选课记录
+// enrollmentListSpec 选课记录列表支持的排序和筛选参数，Student、Course 为关联表别名
+var enrollmentListSpec = services.ListSpec{
+	IDColumn: "enrollments.id",
+	Sorts: map[string]string{
+		"id":           "enrollments.id",
+		"enroll_time":  "enrollments.enroll_time",
+		"student_name": "Student.name",
+		"course_name":  "Course.name",
+	},
+	Filters: map[string]string{
+		"course_id":  "enrollments.course_id = ?",
+		"student_id": "enrollments.student_id = ?",
+		"semester":   "Course.semester = ?",
+	},
+	KeywordColumns: []string{"Student.name", "Student.username", "Course.name"},
+}
+
+// GetEnrollments 获取选课记录，支持分页、排序、按课程/学生/学期筛选和关键字搜索
 func GetEnrollments(c *gin.Context) {
-	var enrollments []models.Enrollment
+	q, ok := bindListQuery(c, enrollmentListSpec)
+	if !ok {
+		return
+	}
 
-	// 预加载学生和课程信息
-	if err := database.DB.Preload("Student").Preload("Course").Find(&enrollments).Error; err != nil {
+	// 关联学生和课程信息，筛选和排序可以使用关联表字段
+	query, total, err := q.Apply(database.DB.Model(&models.Enrollment{}).Joins("Student").Joins("Course"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取选课记录失败: "+err.Error())
+		return
+	}
+	var enrollments []models.Enrollment
+	if err := query.Find(&enrollments).Error; err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取选课记录失败: "+err.Error())
 		return
 	}
 
 	// 构造返回数据
 	type enrollmentResponse struct {
-		ID          uint      `json:"id"`
-		StudentID   uint      `json:"studentId"`
-		StudentName string    `json:"studentName"`
-		CourseID    uint      `json:"courseId"`
-		CourseName  string    `json:"courseName"`
-		EnrollTime  time.Time `json:"enrollTime"`
+		ID              uint      `json:"id"`
+		StudentID       uint      `json:"studentId"`
+		StudentName     string    `json:"studentName"`
+		StudentUsername string    `json:"studentUsername"` // 学号
+		CourseID        uint      `json:"courseId"`
+		CourseName      string    `json:"courseName"`
+		EnrollTime      time.Time `json:"enrollTime"`
 	}
 
-	var result []enrollmentResponse
+	result := make([]enrollmentResponse, 0, len(enrollments))
+	var lastID uint
 	for _, enrollment := range enrollments {
+		lastID = enrollment.ID
 		result = append(result, enrollmentResponse{
-			ID:          enrollment.ID,
-			StudentID:   enrollment.StudentID,
-			StudentName: enrollment.Student.Name,
-			CourseID:    enrollment.CourseID,
-			CourseName:  enrollment.Course.Name,
-			EnrollTime:  enrollment.EnrollTime,
+			ID:              enrollment.ID,
+			StudentID:       enrollment.StudentID,
+			StudentName:     enrollment.Student.Name,
+			StudentUsername: enrollment.Student.Username,
+			CourseID:        enrollment.CourseID,
+			CourseName:      enrollment.Course.Name,
+			EnrollTime:      enrollment.EnrollTime,
 		})
 	}
 
-	response.Success(c, result)
+	respondList(c, q, result, total, len(enrollments), lastID)
 }
 
 // CreateEnrollment 添加选课记录
@@ -69,13 +101,8 @@ func CreateEnrollment(c *gin.Context) {
 		return
 	}
 
-	var course models.Course
-	if err := database.DB.First(&course, req.CourseID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			response.Error(c, http.StatusBadRequest, "课程不存在")
-			return
-		}
-		response.Error(c, http.StatusInternalServerError, "查询课程失败")
+	// 只有课程教师和管理员可以为课程添加学生
+	if _, ok := authorizeCourseOwner(c, req.CourseID); !ok {
 		return
 	}
 
@@ -124,7 +151,23 @@ func CreateEnrollment(c *gin.Context) {
 
 // DeleteEnrollment 删除选课记录
 func DeleteEnrollment(c *gin.Context) {
-	id := c.Param("id")
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的选课记录ID")
+		return
+	}
+
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	// 只有课程教师和管理员可以删除选课记录
+	if _, err := authorizationService.AuthorizeEnrollment(userID, role, uint(id)); err != nil {
+		respondAuthorizationError(c, err, "选课记录不存在")
+		return
+	}
 
 	var enrollment models.Enrollment
 	if err := database.DB.Preload("Student").Preload("Course").First(&enrollment, id).Error; err != nil {
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// utf8BOM 写在 CSV 开头，保证 Excel 正确识别中文
+const utf8BOM = "\xEF\xBB\xBF"
+
+// setAttachment 设置下载文件名，文件名按 RFC 2231 编码，避免特殊字符破坏响应头
+func setAttachment(c *gin.Context, filename string) {
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
+
+// ExportCheckinRecords 导出某次签到的考勤表
+func ExportCheckinRecords(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("session_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的会话ID")
+		return
+	}
+
+	if format := c.DefaultQuery("format", "csv"); format != "csv" {
+		response.Error(c, http.StatusBadRequest, "不支持的导出格式: "+format)
+		return
+	}
+
+	if _, ok := authorizeSession(c, uint(sessionID)); !ok {
+		return
+	}
+
+	records, err := services.GetCheckinRecordsBySession(uint(sessionID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取记录失败")
+		return
+	}
+
+	filename := fmt.Sprintf("attendance_%d.csv", sessionID)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	setAttachment(c, filename)
+	c.Status(http.StatusOK)
+
+	c.Writer.WriteString(utf8BOM)
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"学号", "姓名", "状态", "签到时间"})
+	for _, record := range records {
+		checkinTime := ""
+		if t, ok := record["checkin_time"].(string); ok {
+			checkinTime = t
+		}
+		status, _ := record["status"].(string)
+		writer.Write([]string{
+			services.SanitizeCell(fmt.Sprint(record["username"])),
+			services.SanitizeCell(fmt.Sprint(record["student_name"])),
+			services.StatusLabel(status),
+			checkinTime,
+		})
+	}
+	writer.Flush()
+}
+
+// ExportCourseAttendanceMatrix 导出课程考勤矩阵（xlsx），学生为行、签到会话为列
+func ExportCourseAttendanceMatrix(c *gin.Context) {
+	courseID, ok := parseCourseIDAndCheckAccess(c)
+	if !ok {
+		return
+	}
+
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	matrix, err := reportService.GetCourseAttendanceMatrix(courseID, dateRange)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取考勤数据失败: "+err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("attendance_%s.xlsx", matrix.Course.CourseCode)
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	setAttachment(c, filename)
+	c.Status(http.StatusOK)
+
+	if err := services.WriteAttendanceMatrixXLSX(c.Writer, matrix, dateRange); err != nil {
+		log.Printf("导出课程 %d 考勤矩阵失败: %v", courseID, err)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindListQuery 解析列表接口的分页、排序和筛选参数，参数错误时写入错误响应
+func bindListQuery(c *gin.Context, spec services.ListSpec) (*services.ListQuery, bool) {
+	q, err := services.ParseListQuery(c.Request.URL.Query(), spec)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return nil, false
+	}
+	return q, true
+}
+
+// respondList 返回列表结果：分页请求返回带总数的分页结构，否则直接返回数组并通过 X-Total-Count 给出总数
+// n 为本页记录数，lastID 为本页最后一条记录的 ID
+func respondList(c *gin.Context, q *services.ListQuery, items interface{}, total int64, n int, lastID uint) {
+	if !q.Paginated() {
+		c.Header("X-Total-Count", strconv.Itoa(n))
+		response.Success(c, items)
+		return
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	response.Success(c, q.Result(items, total, n, lastID))
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetLoginLockouts 管理员查看登录失败限制记录，默认只返回锁定中的记录，all=true 时返回全部
+// 支持分页、排序、按类型筛选和按用户名或 IP 搜索
+func GetLoginLockouts(c *gin.Context) {
+	q, ok := bindListQuery(c, services.LoginThrottleListSpec)
+	if !ok {
+		return
+	}
+
+	throttles, total, err := loginThrottleService.ListThrottles(q, c.Query("all") != "true")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取登录锁定记录失败")
+		return
+	}
+
+	result := make([]gin.H, 0, len(throttles))
+	for _, throttle := range throttles {
+		result = append(result, gin.H{
+			"id":           throttle.ID,
+			"kind":         throttle.Kind,
+			"identifier":   throttle.Identifier,
+			"failedCount":  throttle.FailedCount,
+			"lockoutCount": throttle.LockoutCount,
+			"lockedUntil":  throttle.LockedUntil,
+			"lastFailedAt": throttle.LastFailedAt,
+		})
+	}
+
+	var lastID uint
+	if len(throttles) > 0 {
+		lastID = throttles[len(throttles)-1].ID
+	}
+	respondList(c, q, result, total, len(throttles), lastID)
+}
+
+// ClearLoginLockout 管理员解除登录锁定
+func ClearLoginLockout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的记录ID")
+		return
+	}
+
+	if err := loginThrottleService.ClearThrottle(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "锁定记录不存在")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "解除锁定失败")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已解除锁定"})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"backend/config"
+	"backend/internal/services"
+	"backend/pkg/response"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+var oidcService = &services.OIDCService{}
+
+// GetOIDCConfig 登录页获取是否启用单点登录
+func GetOIDCConfig(c *gin.Context) {
+	response.Success(c, gin.H{
+		"enabled": oidcService.Enabled(),
+		"name":    config.Cfg.OIDCDisplayName,
+	})
+}
+
+// OIDCLogin 跳转到身份提供方登录
+func OIDCLogin(c *gin.Context) {
+	authURL, err := oidcService.BeginLogin(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("发起单点登录失败: %v", err)
+		redirectToLoginPage(c, "sso_error", "单点登录服务暂不可用，请稍后再试")
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 身份提供方登录后的回调，校验通过后带一次性换取码跳回前端登录页
+func OIDCCallback(c *gin.Context) {
+	if errMsg := c.Query("error"); errMsg != "" {
+		log.Printf("身份提供方返回错误: %s %s", errMsg, c.Query("error_description"))
+		redirectToLoginPage(c, "sso_error", "单点登录已取消或失败")
+		return
+	}
+
+	code, err := oidcService.HandleCallback(c.Request.Context(), c.Query("state"), c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOIDCState), errors.Is(err, services.ErrOIDCAccountUnavailable):
+			redirectToLoginPage(c, "sso_error", err.Error())
+		default:
+			log.Printf("单点登录回调失败: %v", err)
+			redirectToLoginPage(c, "sso_error", "单点登录失败，请稍后再试")
+		}
+		return
+	}
+
+	redirectToLoginPage(c, "sso_code", code)
+}
+
+// OIDCExchange 前端使用一次性换取码获取登录令牌
+func OIDCExchange(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := oidcService.Exchange(req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOIDCState) || errors.Is(err, services.ErrOIDCAccountUnavailable) {
+			response.Error(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "登录失败")
+		return
+	}
+
+	finishLogin(c, user, user.Username)
+}
+
+// redirectToLoginPage 跳转回前端登录页，并通过查询参数传递结果
+func redirectToLoginPage(c *gin.Context, key, value string) {
+	c.Redirect(http.StatusFound, config.Cfg.FrontendURL+"/login?"+url.Values{key: {value}}.Encode())
+}
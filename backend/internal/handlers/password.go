@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"backend/pkg/utils"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var passwordService = &services.PasswordService{}
+
+// ChangePassword 当前登录用户修改密码，需要提供当前密码
+// 修改后其他设备上的令牌全部失效，当前设备返回新的令牌
+func ChangePassword(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=6"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := passwordService.ChangePassword(userID, req.CurrentPassword, req.NewPassword); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := userService.GetUserByID(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取用户信息失败")
+		return
+	}
+	tokens, err := authService.IssueTokens(user)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成 Token 失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":       "密码修改成功",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// LoginChangePassword 必须修改初始密码的用户使用登录时返回的专用令牌设置新密码，成功后签发正式令牌
+func LoginChangePassword(c *gin.Context) {
+	var req struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required,min=6"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	claims, err := utils.ValidatePurposeToken(req.Token, services.PurposePasswordChange)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "登录已过期，请重新输入密码")
+		return
+	}
+
+	if err := passwordService.CompleteRequiredChange(claims.UserID, req.NewPassword); err != nil {
+		if errors.Is(err, services.ErrPasswordChangeNotRequired) {
+			response.Error(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := userService.GetUserByID(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取用户信息失败")
+		return
+	}
+	respondLoginTokens(c, user, nil)
+}
+
+// ForgotPassword 找回密码，向用户邮箱发送重置链接
+// 无论账号是否存在都返回相同结果，避免账号被探测；同一账号和 IP 的请求按时间窗口限流
+func ForgotPassword(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required"` // 用户名或邮箱
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请输入用户名或邮箱")
+		return
+	}
+
+	if err := loginThrottleService.AllowResetRequest(req.Username, c.ClientIP()); err != nil {
+		var lockedErr *services.LockedError
+		if errors.As(err, &lockedErr) {
+			response.Error(c, http.StatusTooManyRequests, lockedErr.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "找回密码失败")
+		return
+	}
+
+	// 查找账号和发送邮件都在后台进行，响应时间与账号是否存在无关
+	go func(identifier string) {
+		if err := passwordService.RequestReset(identifier); err != nil {
+			log.Printf("发送找回密码邮件失败: %v", err)
+		}
+	}(req.Username)
+
+	response.Success(c, gin.H{"message": "如果账号存在且绑定了邮箱，重置链接已发送到该邮箱"})
+}
+
+// ResetPassword 使用邮件中的重置令牌设置新密码
+func ResetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := passwordService.ResetPassword(req.Token, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "重置密码失败")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "密码已重置，请使用新密码登录"})
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var reportService = &services.ReportService{}
+
+// parseDateRange 解析查询参数 start_date / end_date（格式 2006-01-02，结束日期包含当天）
+func parseDateRange(c *gin.Context) (services.DateRange, error) {
+	var dateRange services.DateRange
+
+	if startStr := c.Query("start_date"); startStr != "" {
+		start, err := time.ParseInLocation("2006-01-02", startStr, time.Local)
+		if err != nil {
+			return dateRange, errors.New("开始日期格式错误，应为 YYYY-MM-DD")
+		}
+		dateRange.Start = start
+	}
+
+	if endStr := c.Query("end_date"); endStr != "" {
+		end, err := time.ParseInLocation("2006-01-02", endStr, time.Local)
+		if err != nil {
+			return dateRange, errors.New("结束日期格式错误，应为 YYYY-MM-DD")
+		}
+		dateRange.End = end.AddDate(0, 0, 1)
+	}
+
+	if !dateRange.Start.IsZero() && !dateRange.End.IsZero() && !dateRange.Start.Before(dateRange.End) {
+		return dateRange, errors.New("开始日期不能晚于结束日期")
+	}
+
+	return dateRange, nil
+}
+
+// parseCourseIDAndCheckAccess 解析路径中的课程ID并校验当前用户是否可查看该课程，失败时写入错误响应
+func parseCourseIDAndCheckAccess(c *gin.Context) (uint, bool) {
+	courseID, err := strconv.ParseUint(c.Param("course_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的课程ID")
+		return 0, false
+	}
+
+	if _, ok := authorizeCourse(c, uint(courseID)); !ok {
+		return 0, false
+	}
+
+	return uint(courseID), true
+}
+
+// GetCourseStudentReport 获取课程内每个学生的考勤统计
+func GetCourseStudentReport(c *gin.Context) {
+	courseID, ok := parseCourseIDAndCheckAccess(c)
+	if !ok {
+		return
+	}
+
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := reportService.GetCourseStudentReport(courseID, dateRange)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取考勤统计失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// GetCourseAttendanceTrend 获取课程考勤趋势，group_by=week 时按周汇总
+func GetCourseAttendanceTrend(c *gin.Context) {
+	courseID, ok := parseCourseIDAndCheckAccess(c)
+	if !ok {
+		return
+	}
+	userID, role, _ := currentUser(c)
+
+	groupBy := c.DefaultQuery("group_by", "session")
+	if groupBy != "session" && groupBy != "week" {
+		response.Error(c, http.StatusBadRequest, "group_by 仅支持 session 或 week")
+		return
+	}
+
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	points, err := reportService.GetCourseAttendanceTrend(courseID, userID, role, dateRange)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取考勤趋势失败: "+err.Error())
+		return
+	}
+
+	if groupBy == "week" {
+		response.Success(c, services.AggregateAttendanceByWeek(points))
+		return
+	}
+
+	response.Success(c, points)
+}
+
+// parseBucketSeconds 解析直方图区间宽度（秒），默认 60 秒
+func parseBucketSeconds(c *gin.Context) (int, error) {
+	bucket, err := strconv.Atoi(c.DefaultQuery("bucket", "60"))
+	if err != nil || bucket < 10 || bucket > 3600 {
+		return 0, errors.New("bucket 应为 10 到 3600 之间的秒数")
+	}
+	return bucket, nil
+}
+
+// GetSessionArrivalDistribution 获取单次签到的到达时间分布
+func GetSessionArrivalDistribution(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("session_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的会话ID")
+		return
+	}
+
+	bucket, err := parseBucketSeconds(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := authorizeSession(c, uint(sessionID)); !ok {
+		return
+	}
+
+	distribution, err := reportService.GetSessionArrivalDistribution(uint(sessionID), bucket)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取签到时间分布失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, distribution)
+}
+
+// GetCourseArrivalDistribution 获取课程汇总的到达时间分布
+func GetCourseArrivalDistribution(c *gin.Context) {
+	courseID, ok := parseCourseIDAndCheckAccess(c)
+	if !ok {
+		return
+	}
+
+	bucket, err := parseBucketSeconds(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	distribution, err := reportService.GetCourseArrivalDistribution(courseID, dateRange, bucket)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取签到时间分布失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, distribution)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	models "backend/internal/model"
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var scoringService = &services.ScoringService{}
+
+func scoringPolicyResponse(policy models.ScoringPolicy) gin.H {
+	return gin.H{
+		"course_id":      policy.CourseID,
+		"full_score":     policy.FullScore,
+		"present_weight": policy.PresentWeight,
+		"late_weight":    policy.LateWeight,
+		"absent_weight":  policy.AbsentWeight,
+		"excused_weight": policy.ExcusedWeight,
+		"max_absences":   policy.MaxAbsences,
+		"cap":            policy.Cap,
+	}
+}
+
+// GetScoringPolicy 获取课程考勤成绩规则，未配置时返回默认规则
+func GetScoringPolicy(c *gin.Context) {
+	courseID, ok := parseCourseIDAndCheckAccess(c)
+	if !ok {
+		return
+	}
+
+	policy, err := scoringService.GetPolicy(courseID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取成绩规则失败")
+		return
+	}
+
+	response.Success(c, scoringPolicyResponse(policy))
+}
+
+// UpdateScoringPolicy 设置课程考勤成绩规则（课程教师或管理员）
+func UpdateScoringPolicy(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("course_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的课程ID")
+		return
+	}
+
+	var req struct {
+		FullScore     float64  `json:"full_score" binding:"required"`
+		PresentWeight float64  `json:"present_weight"`
+		LateWeight    float64  `json:"late_weight"`
+		AbsentWeight  float64  `json:"absent_weight"`
+		ExcusedWeight float64  `json:"excused_weight"`
+		MaxAbsences   int      `json:"max_absences"`
+		Cap           *float64 `json:"cap"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+	if _, ok := authorizeCourseOwner(c, uint(courseID)); !ok {
+		return
+	}
+
+	policy, err := scoringService.SavePolicy(models.ScoringPolicy{
+		CourseID:      uint(courseID),
+		FullScore:     req.FullScore,
+		PresentWeight: req.PresentWeight,
+		LateWeight:    req.LateWeight,
+		AbsentWeight:  req.AbsentWeight,
+		ExcusedWeight: req.ExcusedWeight,
+		MaxAbsences:   req.MaxAbsences,
+		Cap:           req.Cap,
+		UpdatedBy:     userID,
+	})
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, scoringPolicyResponse(*policy))
+}
+
+// GetCourseScores 计算课程内每个学生的考勤成绩及计算说明
+func GetCourseScores(c *gin.Context) {
+	courseID, ok := parseCourseIDAndCheckAccess(c)
+	if !ok {
+		return
+	}
+
+	policy, scores, err := scoringService.GetCourseScores(courseID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "计算考勤成绩失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"policy": scoringPolicyResponse(*policy),
+		"scores": scores,
+	})
+}
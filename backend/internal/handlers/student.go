@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var studentService = &services.StudentService{}
+
+// GetMyEnrolledCourses 学生获取本人所选课程及各课程考勤汇总
+func GetMyEnrolledCourses(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	summaries, err := studentService.GetCourseSummaries(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取课程考勤汇总失败")
+		return
+	}
+
+	response.Success(c, summaries)
+}
+
+// GetMyAttendance 学生获取本人每次签到的状态，可按 course_id 过滤
+func GetMyAttendance(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var courseID uint64
+	if courseIDStr := c.Query("course_id"); courseIDStr != "" {
+		var err error
+		courseID, err = strconv.ParseUint(courseIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的课程ID")
+			return
+		}
+	}
+
+	history, err := studentService.GetAttendanceHistory(userID, uint(courseID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取考勤记录失败")
+		return
+	}
+
+	response.Success(c, history)
+}
+
+// GetMyRequests 学生获取本人的请假与申诉，默认只返回待审核的申请
+func GetMyRequests(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	status := c.DefaultQuery("status", "pending")
+	if status == "all" {
+		status = ""
+	}
+
+	requests, err := attendanceRequestService.GetStudentRequests(userID, status)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取申请列表失败")
+		return
+	}
+
+	result := make([]gin.H, 0, len(requests))
+	for _, request := range requests {
+		result = append(result, attendanceRequestResponse(request))
+	}
+	response.Success(c, result)
+}
+
+// CreateMyRequest 学生针对某次签到提交请假或申诉
+func CreateMyRequest(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req struct {
+		Type      string `json:"type" binding:"required,oneof=leave appeal"`
+		SessionID uint   `json:"session_id" binding:"required"`
+		Reason    string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	request, err := attendanceRequestService.CreateRequest(userID, req.Type, req.SessionID, req.Reason)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"id":      request.ID,
+		"status":  request.Status,
+		"message": "申请已提交",
+	})
+}
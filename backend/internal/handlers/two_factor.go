@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	models "backend/internal/model"
+	"backend/internal/services"
+	"backend/pkg/response"
+	"backend/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var twoFactorService = &services.TwoFactorService{}
+
+// respondTwoFactorChallenge 密码校验通过但需要完成双因素认证时，返回登录第二步使用的令牌
+func respondTwoFactorChallenge(c *gin.Context, userID uint, purpose, flag string) {
+	challenge, err := utils.GeneratePurposeToken(userID, purpose, services.TwoFactorChallengeTTL)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成 Token 失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		flag:              true,
+		"challenge_token": challenge,
+		"expires_in":      int(services.TwoFactorChallengeTTL.Seconds()),
+	})
+}
+
+// challengeUser 校验登录第二步的令牌并返回对应用户，失败时写入错误响应
+func challengeUser(c *gin.Context, challengeToken, purpose string) (*models.User, bool) {
+	claims, err := utils.ValidatePurposeToken(challengeToken, purpose)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "登录已过期，请重新输入密码")
+		return nil, false
+	}
+
+	user, err := userService.GetUserByID(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "登录已过期，请重新输入密码")
+		return nil, false
+	}
+	return user, true
+}
+
+// LoginTwoFactor 登录第二步：校验验证码或恢复码后签发令牌
+func LoginTwoFactor(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请输入验证码")
+		return
+	}
+
+	user, ok := challengeUser(c, req.ChallengeToken, services.PurposeTwoFactorLogin)
+	if !ok {
+		return
+	}
+
+	clientIP := c.ClientIP()
+	if !checkLoginAllowed(c, user.Username, clientIP) {
+		return
+	}
+	if err := twoFactorService.Verify(user.ID, req.Code); err != nil {
+		if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			loginThrottleService.RecordFailure(user.Username, clientIP)
+			response.Error(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loginThrottleService.RecordSuccess(user.Username)
+	respondLoginTokens(c, user, nil)
+}
+
+// LoginTwoFactorSetup 角色强制要求双因素认证但尚未启用时，在登录过程中生成绑定二维码
+func LoginTwoFactorSetup(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	user, ok := challengeUser(c, req.ChallengeToken, services.PurposeTwoFactorSetup)
+	if !ok {
+		return
+	}
+
+	setup, err := twoFactorService.BeginSetup(user)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// LoginTwoFactorEnable 在登录过程中确认绑定，启用后直接完成登录并返回恢复码
+func LoginTwoFactorEnable(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请输入验证码")
+		return
+	}
+
+	user, ok := challengeUser(c, req.ChallengeToken, services.PurposeTwoFactorSetup)
+	if !ok {
+		return
+	}
+
+	clientIP := c.ClientIP()
+	if !checkLoginAllowed(c, user.Username, clientIP) {
+		return
+	}
+	codes, err := twoFactorService.Enable(user.ID, req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			loginThrottleService.RecordFailure(user.Username, clientIP)
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loginThrottleService.RecordSuccess(user.Username)
+	respondLoginTokens(c, user, gin.H{"recovery_codes": codes})
+}
+
+// GetTwoFactorStatus 获取当前用户的双因素认证状态
+func GetTwoFactorStatus(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	enabled, err := twoFactorService.IsEnabled(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取双因素认证状态失败")
+		return
+	}
+	required, err := twoFactorService.IsRequired(role)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取双因素认证状态失败")
+		return
+	}
+	remaining, err := twoFactorService.RemainingRecoveryCodes(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取双因素认证状态失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"enabled":                  enabled,
+		"required":                 required,
+		"remaining_recovery_codes": remaining,
+	})
+}
+
+// SetupTwoFactor 生成新的 TOTP 密钥和绑定二维码
+func SetupTwoFactor(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	user, err := userService.GetUserByID(userID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	setup, err := twoFactorService.BeginSetup(user)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// EnableTwoFactor 使用验证码确认绑定并启用双因素认证，返回恢复码
+func EnableTwoFactor(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请输入验证码")
+		return
+	}
+
+	codes, err := twoFactorService.Enable(userID, req.Code)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// DisableTwoFactor 关闭双因素认证，需要提供密码和验证码，角色强制要求时不能关闭
+func DisableTwoFactor(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请输入密码和验证码")
+		return
+	}
+
+	required, err := twoFactorService.IsRequired(role)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "关闭双因素认证失败")
+		return
+	}
+	if required {
+		response.Error(c, http.StatusForbidden, "您的角色要求必须启用双因素认证")
+		return
+	}
+
+	user, err := userService.GetUserByID(userID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err := authService.CheckPassword(user, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			response.Error(c, http.StatusBadRequest, "密码错误")
+			return
+		}
+		response.Error(c, http.StatusServiceUnavailable, "认证服务暂不可用，请稍后再试")
+		return
+	}
+	if err := twoFactorService.Verify(userID, req.Code); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := twoFactorService.Disable(userID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "关闭双因素认证失败")
+		return
+	}
+
+	// 关闭后其他设备上的令牌全部失效，当前设备返回新的令牌
+	user, err = userService.GetUserByID(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取用户信息失败")
+		return
+	}
+	tokens, err := authService.IssueTokens(user)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成 Token 失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":       "已关闭双因素认证",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，需要提供验证码
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请输入验证码")
+		return
+	}
+
+	if err := twoFactorService.Verify(userID, req.Code); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := twoFactorService.RegenerateRecoveryCodes(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成恢复码失败")
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// ResetUserTwoFactor 管理员为丢失验证器的用户关闭双因素认证，该用户需要重新登录
+func ResetUserTwoFactor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	if _, err := userService.GetUserByID(uint(id)); err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err := twoFactorService.Disable(uint(id)); err != nil {
+		response.Error(c, http.StatusInternalServerError, "重置双因素认证失败")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已重置该用户的双因素认证"})
+}
+
+// GetTwoFactorPolicy 获取强制启用双因素认证的角色
+func GetTwoFactorPolicy(c *gin.Context) {
+	roles, err := twoFactorService.GetRequiredRoles()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取双因素认证策略失败")
+		return
+	}
+
+	response.Success(c, gin.H{"required_roles": roles})
+}
+
+// UpdateTwoFactorPolicy 设置强制启用双因素认证的角色
+func UpdateTwoFactorPolicy(c *gin.Context) {
+	var req struct {
+		RequiredRoles []string `json:"required_roles" binding:"dive,oneof=admin teacher assistant student"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := twoFactorService.SetRequiredRoles(req.RequiredRoles); err != nil {
+		response.Error(c, http.StatusInternalServerError, "保存双因素认证策略失败")
+		return
+	}
+
+	response.Success(c, gin.H{"required_roles": req.RequiredRoles})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	models "backend/internal/model"
 	"backend/internal/services"
 	"backend/pkg/response"
 	"net/http"
@@ -11,36 +12,50 @@ import (
 
 var userService = &services.UserService{}
 
-// 获取所有用户
+// 获取用户列表，支持分页、排序、按角色筛选和关键字搜索
 func GetUsers(c *gin.Context) {
-	users, err := userService.GetAllUsers()
+	q, ok := bindListQuery(c, services.UserListSpec)
+	if !ok {
+		return
+	}
+
+	users, total, err := userService.ListUsers(q)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取用户列表失败")
 		return
 	}
 
 	// 清除密码哈希值，不返回给前端
+	var lastID uint
 	for i := range users {
 		users[i].PasswordHash = ""
+		lastID = users[i].ID
 	}
 
-	response.Success(c, users)
+	respondList(c, q, users, total, len(users), lastID)
 }
 
-// 获取所有学生
+// 获取学生列表，支持分页、排序、按课程筛选和关键字搜索
 func GetStudents(c *gin.Context) {
-	students, err := userService.GetStudents()
+	q, ok := bindListQuery(c, services.StudentListSpec)
+	if !ok {
+		return
+	}
+
+	students, total, err := userService.ListStudents(q)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "获取学生列表失败")
 		return
 	}
 
 	// 清除密码哈希值，不返回给前端
+	var lastID uint
 	for i := range students {
 		students[i].PasswordHash = ""
+		lastID = students[i].ID
 	}
 
-	response.Success(c, students)
+	respondList(c, q, students, total, len(students), lastID)
 }
 
 // 获取所有教师
@@ -64,9 +79,11 @@ func CreateUser(c *gin.Context) {
 	var req struct {
 		Username string `json:"username" binding:"required"`
 		Name     string `json:"name" binding:"required"`
-		Password string `json:"password" binding:"required,min=6"`
-		Role     string `json:"role" binding:"required,oneof=admin teacher student"`
+		Password string `json:"password" binding:"omitempty,min=6"`
+		Role     string `json:"role" binding:"required,oneof=admin teacher assistant student"`
 		Email    string `json:"email" binding:"required,email"`
+		// 为 true 时忽略 password，由系统生成随机初始密码
+		GeneratePassword bool `json:"generate_password"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -74,13 +91,23 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := userService.CreateUser(req.Username, req.Name, req.Password, req.Role, req.Email)
+	password, generated, ok := resolveInitialPassword(c, req.Password, req.GeneratePassword)
+	if !ok {
+		return
+	}
+
+	user, err := userService.CreateUser(req.Username, req.Name, password, req.Role, req.Email)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	response.Success(c, user)
+	// 生成的初始密码只在此次响应中返回一次
+	user.PasswordHash = ""
+	response.Success(c, struct {
+		*models.User
+		InitialPassword string `json:"initial_password,omitempty"`
+	}{user, generated})
 }
 
 // 更新用户
@@ -93,7 +120,7 @@ func UpdateUser(c *gin.Context) {
 
 	var req struct {
 		Name  string `json:"name" binding:"required"`
-		Role  string `json:"role" binding:"required,oneof=admin teacher student"`
+		Role  string `json:"role" binding:"required,oneof=admin teacher assistant student"`
 		Email string `json:"email" binding:"required,email"`
 	}
 
@@ -155,7 +182,8 @@ func ResetUserPassword(c *gin.Context) {
 	}
 
 	var req struct {
-		Password string `json:"password" binding:"required,min=6"`
+		Password string `json:"password" binding:"omitempty,min=6"`
+		Generate bool   `json:"generate"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -163,11 +191,40 @@ func ResetUserPassword(c *gin.Context) {
 		return
 	}
 
-	err = userService.UpdatePassword(uint(id), req.Password)
+	password, generated, ok := resolveInitialPassword(c, req.Password, req.Generate)
+	if !ok {
+		return
+	}
+
+	// 管理员重置后，用户下次登录必须修改密码
+	err = userService.UpdatePassword(uint(id), password, true)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	response.Success(c, gin.H{"message": "密码重置成功"})
+	data := gin.H{"message": "密码重置成功"}
+	if generated != "" {
+		data["initial_password"] = generated
+	}
+	response.Success(c, data)
+}
+
+// 确定管理员设置的初始密码：要求生成时返回随机密码，否则必须提供密码
+// 第二个返回值仅在密码由系统生成时非空
+func resolveInitialPassword(c *gin.Context, password string, generate bool) (string, string, bool) {
+	if !generate {
+		if password == "" {
+			response.Error(c, http.StatusBadRequest, "请设置密码或选择自动生成")
+			return "", "", false
+		}
+		return password, "", true
+	}
+
+	generated, err := services.GenerateInitialPassword()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成初始密码失败")
+		return "", "", false
+	}
+	return generated, generated, true
 }
\ No newline at end of file
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"backend/internal/services"
+	"backend/pkg/response"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 10 << 20
+
+var userImportService = &services.UserImportService{}
+
+// ImportUsers 上传 CSV/XLSX 文件并预检，返回每一行的校验结果，不写入数据库
+// 导入的账号首次登录时必须修改初始密码
+func ImportUsers(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "请上传导入文件")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		response.Error(c, http.StatusBadRequest, "导入文件不能超过 10MB")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "读取导入文件失败")
+		return
+	}
+	defer file.Close()
+
+	rows, err := services.ParseUserImportFile(fileHeader.Filename, file)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// generate_passwords=true 时为所有行生成随机初始密码，避免统一的默认密码
+	generateAll := c.PostForm("generate_passwords") == "true"
+	status, err := userImportService.Validate(userID, fileHeader.Filename, rows, generateAll)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "预检失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// CommitUserImport 确认导入，后台分批写入预检通过的行
+func CommitUserImport(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	status, err := userImportService.Commit(c.Param("id"), userID)
+	if err != nil {
+		respondUserImportError(c, err)
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// GetUserImport 获取导入任务的进度
+func GetUserImport(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	status, err := userImportService.GetStatus(c.Param("id"), userID)
+	if err != nil {
+		respondUserImportError(c, err)
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// DownloadUserImportResult 下载导入结果（csv 或 xlsx），系统生成的初始密码只在导入完成后的第一次下载中返回
+func DownloadUserImportResult(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	format := c.DefaultQuery("format", "xlsx")
+	if format != "csv" && format != "xlsx" {
+		response.Error(c, http.StatusBadRequest, "不支持的导出格式: "+format)
+		return
+	}
+
+	rows, err := userImportService.ResultRows(c.Param("id"), userID)
+	if err != nil {
+		respondUserImportError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("user_import_%s.%s", c.Param("id"), format)
+	setAttachment(c, filename)
+	c.Header("Cache-Control", "no-store")
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		c.Writer.WriteString(utf8BOM)
+		writer := csv.NewWriter(c.Writer)
+		writer.Write(services.UserImportResultHeader)
+		for _, row := range rows {
+			writer.Write(row.Values())
+		}
+		writer.Flush()
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Status(http.StatusOK)
+	if err := services.WriteUserImportResultXLSX(c.Writer, rows); err != nil {
+		log.Printf("导出导入结果 %s 失败: %v", c.Param("id"), err)
+	}
+}
+
+// respondUserImportError 将导入任务的错误转换为响应
+func respondUserImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrImportJobNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrImportJobStarted):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	models "backend/internal/model"
+	"backend/internal/services"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var warningService = &services.WarningService{}
+
+type warningRuleRequest struct {
+	CourseID          *uint   `json:"course_id"`
+	Name              string  `json:"name" binding:"required"`
+	MaxAbsences       int     `json:"max_absences"`
+	MinAttendanceRate float64 `json:"min_attendance_rate"`
+	Severity          string  `json:"severity" binding:"required,oneof=info warning critical"`
+	Enabled           *bool   `json:"enabled"`
+}
+
+func (req warningRuleRequest) toInput() services.WarningRuleInput {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return services.WarningRuleInput{
+		CourseID:          req.CourseID,
+		Name:              req.Name,
+		MaxAbsences:       req.MaxAbsences,
+		MinAttendanceRate: req.MinAttendanceRate,
+		Severity:          req.Severity,
+		Enabled:           enabled,
+	}
+}
+
+// canManageWarningRule 系统级规则仅管理员可管理，课程规则由课程教师或管理员管理
+func canManageWarningRule(c *gin.Context, courseID *uint) bool {
+	_, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return false
+	}
+	if role == "admin" {
+		return true
+	}
+	if courseID == nil {
+		response.Error(c, http.StatusForbidden, "只有管理员可以管理系统级预警规则")
+		return false
+	}
+	_, ok = authorizeCourseOwner(c, *courseID)
+	return ok
+}
+
+func warningRuleResponse(rule models.WarningRule) gin.H {
+	return gin.H{
+		"id":                  rule.ID,
+		"course_id":           rule.CourseID,
+		"name":                rule.Name,
+		"max_absences":        rule.MaxAbsences,
+		"min_attendance_rate": rule.MinAttendanceRate,
+		"severity":            rule.Severity,
+		"enabled":             rule.Enabled,
+		"created_by":          rule.CreatedBy,
+		"created_at":          rule.CreatedAt,
+	}
+}
+
+// GetWarningRules 获取预警规则，指定 course_id 时返回该课程规则及系统级规则，否则返回当前用户可见的全部规则
+func GetWarningRules(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+	if role == "student" {
+		response.Error(c, http.StatusForbidden, "权限不足")
+		return
+	}
+
+	var courseID *uint
+	if courseIDStr := c.Query("course_id"); courseIDStr != "" {
+		id, err := strconv.ParseUint(courseIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的课程ID")
+			return
+		}
+		if _, ok := authorizeCourse(c, uint(id)); !ok {
+			return
+		}
+		value := uint(id)
+		courseID = &value
+	}
+
+	rules, err := warningService.GetRules(courseID, userID, role)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取预警规则失败")
+		return
+	}
+
+	result := make([]gin.H, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, warningRuleResponse(rule))
+	}
+	response.Success(c, result)
+}
+
+// CreateWarningRule 创建预警规则
+func CreateWarningRule(c *gin.Context) {
+	var req warningRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	if !canManageWarningRule(c, req.CourseID) {
+		return
+	}
+	userID, _, _ := currentUser(c)
+
+	rule, err := warningService.CreateRule(req.toInput(), userID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, warningRuleResponse(*rule))
+}
+
+// UpdateWarningRule 更新预警规则
+func UpdateWarningRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	var req warningRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	rule, err := warningService.GetRuleByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if !canManageWarningRule(c, rule.CourseID) {
+		return
+	}
+
+	rule, err = warningService.UpdateRule(uint(id), req.toInput())
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, warningRuleResponse(*rule))
+}
+
+// DeleteWarningRule 删除预警规则
+func DeleteWarningRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的规则ID")
+		return
+	}
+
+	rule, err := warningService.GetRuleByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if !canManageWarningRule(c, rule.CourseID) {
+		return
+	}
+
+	if err := warningService.DeleteRule(rule.ID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "删除预警规则失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "预警规则已删除"})
+}
+
+// GetWarnings 获取考勤预警列表，默认只返回生效中的预警
+// 教师查看其课程的预警，学生只能查看本人的预警
+func GetWarnings(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	filter := services.WarningFilter{Status: c.DefaultQuery("status", "active")}
+	if filter.Status == "all" {
+		filter.Status = ""
+	}
+	if courseIDStr := c.Query("course_id"); courseIDStr != "" {
+		id, err := strconv.ParseUint(courseIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的课程ID")
+			return
+		}
+		filter.CourseID = uint(id)
+	}
+	if studentIDStr := c.Query("student_id"); studentIDStr != "" {
+		id, err := strconv.ParseUint(studentIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的学生ID")
+			return
+		}
+		filter.StudentID = uint(id)
+	}
+
+	warnings, err := warningService.GetWarnings(userID, role, filter)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取预警列表失败")
+		return
+	}
+
+	result := make([]gin.H, 0, len(warnings))
+	for _, warning := range warnings {
+		result = append(result, gin.H{
+			"id":              warning.ID,
+			"rule_id":         warning.RuleID,
+			"rule_name":       warning.Rule.Name,
+			"course_id":       warning.CourseID,
+			"course_name":     warning.Course.Name,
+			"student_id":      warning.StudentID,
+			"username":        warning.Student.Username,
+			"student_name":    warning.Student.Name,
+			"severity":        warning.Severity,
+			"reason":          warning.Reason,
+			"absent_count":    warning.AbsentCount,
+			"attendance_rate": warning.AttendanceRate,
+			"status":          warning.Status,
+			"triggered_at":    warning.TriggeredAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	response.Success(c, result)
+}
@@ -1,13 +1,25 @@
 package middleware
 
 import (
+	models "backend/internal/model"
+	"backend/pkg/database"
 	"backend/pkg/response"
 	"backend/pkg/utils"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// CurrentTokenVersion 查询用户当前的令牌版本，用户不存在或已删除时返回 false
+var CurrentTokenVersion = func(userID uint) (uint, bool) {
+	var user models.User
+	if err := database.DB.Select("id", "token_version").First(&user, userID).Error; err != nil {
+		return 0, false
+	}
+	return user.TokenVersion, true
+}
+
 func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -26,12 +38,23 @@ func JWTAuth() gin.HandlerFunc {
 		}
 
 		claims, err := utils.ValidateJWT(parts[1])
+		// 专用令牌（如登录第二步的令牌）不能用于访问接口
+		if err == nil && claims.Purpose != "" {
+			err = errors.New("非访问令牌")
+		}
 		if err != nil {
 			response.Error(c, 401, "无效的 Token")
 			c.Abort()
 			return
 		}
 
+		// 用户被删除、修改密码或角色后令牌版本递增，旧令牌立即失效
+		if version, ok := CurrentTokenVersion(claims.UserID); !ok || version != claims.TokenVersion {
+			response.Error(c, 401, "Token 已失效，请重新登录")
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
 		c.Next()
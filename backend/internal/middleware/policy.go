@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"backend/pkg/response"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoutePolicy 声明式路由权限策略，键为 "方法 路由模板"（如 "PUT /api/users/:id"），值为允许访问的角色
+type RoutePolicy map[string][]string
+
+// RouteKey 生成路由在策略中的键
+func RouteKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// Allows 判断角色是否可以访问指定路由，未在策略中声明的路由一律拒绝
+func (p RoutePolicy) Allows(method, fullPath, role string) bool {
+	for _, allowed := range p[RouteKey(method, fullPath)] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize 按路由策略校验当前用户角色，需在 JWTAuth 之后使用，拒绝时记录日志
+func Authorize(policy RoutePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		roleStr, _ := role.(string)
+		fullPath := c.FullPath()
+
+		if !policy.Allows(c.Request.Method, fullPath, roleStr) {
+			userID, _ := c.Get("user_id")
+			if _, declared := policy[RouteKey(c.Request.Method, fullPath)]; !declared {
+				log.Printf("拒绝访问: 路由 %s 未声明权限策略 (用户 %v, 角色 %q, 请求 %s)", RouteKey(c.Request.Method, fullPath), userID, roleStr, c.Request.URL.Path)
+			} else {
+				log.Printf("拒绝访问: 用户 %v 角色 %q 无权访问 %s (请求 %s)", userID, roleStr, RouteKey(c.Request.Method, fullPath), c.Request.URL.Path)
+			}
+			response.Error(c, 403, "权限不足")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AttendanceRequest 学生提交的请假或考勤申诉
+type AttendanceRequest struct {
+	ID            uint           `gorm:"primaryKey"`
+	Type          string         `gorm:"not null"`                       // 类型: leave(请假), appeal(申诉)
+	StudentID     uint           `gorm:"not null;index"`                 // 学生ID
+	Student       User           `gorm:"foreignKey:StudentID"`           // 关联学生
+	CourseID      uint           `gorm:"not null;index"`                 // 课程ID
+	Course        Course         `gorm:"foreignKey:CourseID"`            // 关联课程
+	SessionID     uint           `gorm:"not null"`                       // 签到会话ID
+	Session       CheckinSession `gorm:"foreignKey:SessionID"`           // 关联签到会话
+	Reason        string         `gorm:"not null"`                       // 申请理由
+	Status        string         `gorm:"not null;default:pending;index"` // 状态: pending, approved, rejected
+	ReviewerID    *uint          // 审核人ID
+	ReviewComment string         // 审核意见
+	ReviewedAt    *time.Time     // 审核时间
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"` // 软删除
+}
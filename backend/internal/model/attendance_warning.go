@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AttendanceWarning 考勤预警事件
+type AttendanceWarning struct {
+	ID             uint        `gorm:"primaryKey"`
+	RuleID         uint        `gorm:"not null;index"`                // 触发的规则ID
+	Rule           WarningRule `gorm:"foreignKey:RuleID"`             // 关联规则
+	CourseID       uint        `gorm:"not null;index"`                // 课程ID
+	Course         Course      `gorm:"foreignKey:CourseID"`           // 关联课程
+	StudentID      uint        `gorm:"not null;index"`                // 学生ID
+	Student        User        `gorm:"foreignKey:StudentID"`          // 关联学生
+	Severity       string      `gorm:"not null"`                      // 严重级别: info, warning, critical
+	Reason         string      `gorm:"not null"`                      // 触发原因
+	AbsentCount    int         `gorm:"not null;default:0"`            // 触发时的缺勤次数
+	AttendanceRate float64     `gorm:"not null;default:0"`            // 触发时的出勤率
+	Status         string      `gorm:"not null;default:active;index"` // 状态: active, resolved
+	TriggeredAt    time.Time   `gorm:"not null"`                      // 触发时间
+	ResolvedAt     *time.Time  // 解除时间
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"` // 软删除
+}
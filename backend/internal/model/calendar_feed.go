@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// CalendarFeed 用户的日历订阅令牌，凭令牌无需登录即可订阅个人签到日历
+type CalendarFeed struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;uniqueIndex"`                  // 用户ID
+	User      User   `gorm:"foreignKey:UserID"`                     // 关联用户
+	Token     string `gorm:"not null;uniqueIndex;type:varchar(64)"` // 订阅令牌
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
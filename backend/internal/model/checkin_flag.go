@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CheckinFlag 疑似代签的签到记录标记，等待教师复核
+type CheckinFlag struct {
+	ID            uint          `gorm:"primaryKey"`
+	RecordID      uint          `gorm:"not null;uniqueIndex"`           // 签到记录ID
+	Record        CheckinRecord `gorm:"foreignKey:RecordID"`            // 关联签到记录
+	SessionID     uint          `gorm:"not null;index"`                 // 会话ID
+	CourseID      uint          `gorm:"not null;index"`                 // 课程ID
+	StudentID     uint          `gorm:"not null"`                       // 学生ID
+	Student       User          `gorm:"foreignKey:StudentID"`           // 关联学生
+	Score         int           `gorm:"not null"`                       // 可疑分数
+	Reasons       string        `gorm:"type:text;not null"`             // 命中规则及说明（JSON 数组）
+	Status        string        `gorm:"not null;default:pending;index"` // 状态: pending, confirmed, cleared
+	ReviewerID    *uint         // 复核人ID
+	ReviewComment string        // 复核意见
+	ReviewedAt    *time.Time    // 复核时间
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"` // 软删除
+}
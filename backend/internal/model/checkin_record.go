@@ -13,7 +13,9 @@ type CheckinRecord struct {
 	Student     User      `gorm:"foreignKey:StudentID"`     // 关联学生
 	CourseID    uint      `gorm:"not null"`                 // 课程ID (冗余字段，方便查询)
 	CheckinTime time.Time `gorm:"not null"`                 // 签到时间
-	Status      string    `gorm:"not null;default:present"` // 状态: present, late, absent
+	Status      string    `gorm:"not null;default:present"` // 状态: present, late, absent, excused
+	ClientIP    string    `gorm:"type:varchar(64)"`         // 签到客户端IP（扫码签到时记录）
+	UserAgent   string    `gorm:"type:varchar(512)"`        // 签到客户端 User-Agent
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   gorm.DeletedAt `gorm:"index"` // 软删除
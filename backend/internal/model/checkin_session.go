@@ -7,16 +7,17 @@ import (
 )
 
 type CheckinSession struct {
-	ID          uint      `gorm:"primaryKey"`
-	SessionCode string    `gorm:"uniqueIndex;not null;type:varchar(191)"` // 会话码
-	CourseID    uint      `gorm:"not null"`                               // 课程ID
-	Course      Course    `gorm:"foreignKey:CourseID"`
-	TeacherID   uint      `gorm:"not null"` // 教师ID
-	Teacher     User      `gorm:"foreignKey:TeacherID"`
-	StartTime   time.Time `gorm:"not null"`                // 开始时间
-	Duration    int       `gorm:"not null;default:10"`     // 持续时间(分钟)
-	Status      string    `gorm:"not null;default:active"` // 状态: active, ended
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	DeletedAt   gorm.DeletedAt `gorm:"index"` // 软删除
+	ID            uint      `gorm:"primaryKey"`
+	SessionCode   string    `gorm:"uniqueIndex;not null;type:varchar(191)"` // 会话码
+	CourseID      uint      `gorm:"not null"`                               // 课程ID
+	Course        Course    `gorm:"foreignKey:CourseID"`
+	TeacherID     uint      `gorm:"not null"` // 教师ID
+	Teacher       User      `gorm:"foreignKey:TeacherID"`
+	StartTime     time.Time `gorm:"not null"`                                        // 开始时间
+	Duration      int       `gorm:"not null;default:10"`                             // 持续时间(分钟)
+	Status        string    `gorm:"not null;default:active"`                         // 状态: active, ended
+	PostProcessed bool      `gorm:"column:warning_evaluated;not null;default:false"` // 结束后是否已执行异常签到检测和考勤预警评估，沿用原列名避免重复处理历史会话
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"` // 软删除
 }
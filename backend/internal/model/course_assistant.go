@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CourseAssistant 课程助教分配，助教仅能管理被分配课程的签到
+type CourseAssistant struct {
+	ID          uint   `gorm:"primaryKey"`
+	CourseID    uint   `gorm:"not null;uniqueIndex:idx_course_assistant"` // 课程ID
+	Course      Course `gorm:"foreignKey:CourseID"`                       // 关联课程
+	AssistantID uint   `gorm:"not null;uniqueIndex:idx_course_assistant"` // 助教用户ID
+	Assistant   User   `gorm:"foreignKey:AssistantID"`                    // 关联助教
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"` // 软删除
+}
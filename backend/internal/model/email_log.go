@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailLog 邮件发送记录
+type EmailLog struct {
+	ID        uint      `gorm:"primaryKey"`
+	Kind      string    `gorm:"not null;index;type:varchar(64)"` // 邮件类型，如 weekly_digest
+	UserID    uint      `gorm:"index"`                           // 收件用户ID
+	Recipient string    `gorm:"not null"`                        // 收件地址
+	Subject   string    `gorm:"not null"`                        // 邮件主题
+	Status    string    `gorm:"not null"`                        // 状态: sent, failed
+	Error     string    `gorm:"type:text"`                       // 失败原因
+	SentAt    time.Time `gorm:"not null;index"`                  // 发送时间
+	CreatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginThrottle 登录失败计数和锁定状态，按账号和来源 IP 分别记录
+// 账号按连续失败次数锁定，IP 按固定时间窗口限流；找回密码请求也按固定时间窗口限流
+type LoginThrottle struct {
+	ID              uint       `gorm:"primaryKey"`
+	Kind            string     `gorm:"not null;type:varchar(16);uniqueIndex:idx_login_throttle_key"`  // 类型: user, ip, reset_user, reset_ip
+	Identifier      string     `gorm:"not null;type:varchar(191);uniqueIndex:idx_login_throttle_key"` // 用户名或 IP
+	FailedCount     int        `gorm:"not null;default:0"`                                            // 当前连续失败次数（固定窗口限流时为窗口内次数）
+	LockoutCount    int        `gorm:"not null;default:0"`                                            // 账号累计锁定次数，决定下次锁定时长
+	LockedUntil     *time.Time // 锁定截止时间
+	LastFailedAt    *time.Time // 最近一次失败时间
+	WindowStartedAt *time.Time // 固定窗口限流当前时间窗口的开始时间
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
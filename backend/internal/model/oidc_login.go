@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// OIDCLogin 一次单点登录过程：跳转到身份提供方前创建，回调时校验 state，登录完成后用一次性换取码获取令牌
+type OIDCLogin struct {
+	ID               uint       `gorm:"primaryKey"`
+	StateHash        string     `gorm:"not null;uniqueIndex;type:varchar(64)"` // state 参数 SHA-256 哈希
+	Nonce            string     `gorm:"not null;type:varchar(64)"`             // 写入 ID Token 的随机数，防止令牌重放
+	CodeVerifier     string     `gorm:"not null;type:varchar(128)"`            // PKCE code_verifier
+	ExpiresAt        time.Time  `gorm:"not null"`                              // 过期时间
+	CallbackAt       *time.Time // 身份提供方回调时间，state 只能使用一次
+	UserID           *uint      // 回调成功后关联的用户
+	ExchangeCodeHash string     `gorm:"index;type:varchar(64)"` // 前端换取令牌的一次性换取码哈希
+	ExchangedAt      *time.Time // 换取令牌时间，换取码只能使用一次
+	CreatedAt        time.Time
+}
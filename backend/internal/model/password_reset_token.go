@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordResetToken 找回密码令牌，一次性使用，数据库中只保存令牌的哈希值
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`                        // 用户ID
+	TokenHash string     `gorm:"not null;uniqueIndex;type:varchar(64)"` // 令牌 SHA-256 哈希
+	ExpiresAt time.Time  `gorm:"not null"`                              // 过期时间
+	UsedAt    *time.Time // 使用时间，已使用或被新令牌取代时写入
+	CreatedAt time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// RecoveryCode 双因素认证恢复码，每个只能使用一次，数据库中只保存哈希值
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`            // 用户ID
+	CodeHash  string     `gorm:"not null;type:varchar(64)"` // 恢复码 SHA-256 哈希
+	UsedAt    *time.Time // 使用时间
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，每次使用后轮换，数据库中只保存令牌的哈希值
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`                        // 用户ID
+	TokenHash string     `gorm:"not null;uniqueIndex;type:varchar(64)"` // 令牌 SHA-256 哈希
+	ExpiresAt time.Time  `gorm:"not null"`                              // 过期时间
+	RevokedAt *time.Time // 吊销时间，轮换、登出或强制下线时写入
+	CreatedAt time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScoringPolicy 课程考勤成绩计算规则
+// 各状态权重允许为 0，因此不设置 default 标签，否则 GORM 创建时会把 0 替换为默认值；默认规则见 DefaultScoringPolicy
+type ScoringPolicy struct {
+	ID            uint     `gorm:"primaryKey"`
+	CourseID      uint     `gorm:"not null;uniqueIndex"` // 课程ID
+	FullScore     float64  `gorm:"not null;default:10"`  // 考勤满分，如总评中占 10 分
+	PresentWeight float64  `gorm:"not null"`             // 出勤得分权重
+	LateWeight    float64  `gorm:"not null"`             // 迟到得分权重
+	AbsentWeight  float64  `gorm:"not null"`             // 缺勤得分权重
+	ExcusedWeight float64  `gorm:"not null"`             // 请假得分权重
+	MaxAbsences   int      `gorm:"not null;default:0"`   // 允许的最大缺勤次数，超过则考勤成绩为 0；0 表示不限制
+	Cap           *float64 // 成绩上限，为空表示不限制
+	UpdatedBy     uint     `gorm:"not null"` // 最后修改人ID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"` // 软删除
+}
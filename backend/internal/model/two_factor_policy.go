@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+// TwoFactorPolicy 按角色配置是否强制启用双因素认证
+type TwoFactorPolicy struct {
+	ID        uint   `gorm:"primaryKey"`
+	Role      string `gorm:"not null;uniqueIndex;type:varchar(32)"` // 角色
+	Required  bool   `gorm:"not null;default:false"`                // 是否强制启用
+	UpdatedAt time.Time
+}
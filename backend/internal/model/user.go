@@ -11,8 +11,11 @@ type User struct {
 	Username     string `gorm:"uniqueIndex;not null;type:varchar(191)"` // 学号/工号
 	Name         string `gorm:"not null"`             // 姓名
 	PasswordHash string `gorm:"not null"`             // 密码哈希
-	Role         string `gorm:"not null"`             // student, teacher, admin
+	Role         string `gorm:"not null"`             // student, assistant, teacher, admin
 	Email        string `gorm:"default:null"`         // 邮箱
+	TokenVersion uint   `gorm:"not null;default:0"`   // 令牌版本，递增后已签发的访问令牌全部失效
+	AuthSource   string `gorm:"not null;default:local;type:varchar(20)"` // 账号来源：local 本地密码，ldap 统一身份认证，oidc 单点登录
+	MustChangePassword bool `gorm:"not null;default:false"` // 管理员创建或重置密码后，下次登录时必须先修改密码
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"` // 软删除
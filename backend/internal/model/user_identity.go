@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity 用户在外部身份提供方的身份，首次单点登录时关联，之后按 Issuer 和 Subject 查找用户
+type UserIdentity struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`                                                   // 用户ID
+	Issuer    string `gorm:"not null;uniqueIndex:idx_user_identity_subject;type:varchar(191)"` // 身份提供方
+	Subject   string `gorm:"not null;uniqueIndex:idx_user_identity_subject;type:varchar(191)"` // 身份提供方中的用户标识（sub）
+	CreatedAt time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// UserTwoFactor 用户的 TOTP 双因素认证设置
+type UserTwoFactor struct {
+	ID           uint       `gorm:"primaryKey"`
+	UserID       uint       `gorm:"not null;uniqueIndex"`      // 用户ID
+	Secret       string     `gorm:"not null;type:varchar(64)"` // TOTP 密钥（Base32）
+	Enabled      bool       `gorm:"not null;default:false"`    // 是否已验证启用，未启用的记录为待确认的绑定
+	LastUsedStep int64      `gorm:"not null;default:0"`        // 最近一次使用的验证码时间步，防止验证码被重放
+	EnabledAt    *time.Time // 启用时间
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
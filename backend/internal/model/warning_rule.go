@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WarningRule 考勤预警规则，CourseID 为空表示全校通用规则
+// Enabled 不设置 default 标签，否则 GORM 创建时会把 false 替换为默认值，默认启用由接口层处理
+type WarningRule struct {
+	ID                uint    `gorm:"primaryKey"`
+	CourseID          *uint   `gorm:"index"`                    // 课程ID，为空表示系统级规则
+	Course            *Course `gorm:"foreignKey:CourseID"`      // 关联课程
+	Name              string  `gorm:"not null"`                 // 规则名称
+	MaxAbsences       int     `gorm:"not null;default:0"`       // 缺勤次数达到该值触发，0 表示不启用
+	MinAttendanceRate float64 `gorm:"not null;default:0"`       // 出勤率低于该值触发，0 表示不启用
+	Severity          string  `gorm:"not null;default:warning"` // 严重级别: info, warning, critical
+	Enabled           bool    `gorm:"not null"`                 // 是否启用
+	CreatedBy         uint    `gorm:"not null"`                 // 创建人ID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"` // 软删除
+}
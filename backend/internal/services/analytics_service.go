@@ -0,0 +1,231 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AnalyticsService 全校考勤分析服务（管理员）
+type AnalyticsService struct{}
+
+// AnalyticsDimensions 支持的分组维度
+var AnalyticsDimensions = []string{"semester", "teacher", "course", "weekday", "time_slot"}
+
+// AnalyticsFilter 分析的过滤条件，零值表示不过滤
+type AnalyticsFilter struct {
+	Semester  string
+	TeacherID uint
+	CourseID  uint
+	DateRange DateRange
+}
+
+// AnalyticsRow 一个分组的考勤统计，Expected 为各会话应到人数之和
+// Keys 为各维度的显示名称；教师和课程按 ID 分组，IDs 给出对应的 ID，同名的教师或课程不会合并
+type AnalyticsRow struct {
+	Keys           map[string]string `json:"keys"`
+	IDs            map[string]uint   `json:"ids,omitempty"`
+	Sessions       int               `json:"sessions"`
+	Expected       int               `json:"expected"`
+	Present        int               `json:"present"`
+	Late           int               `json:"late"`
+	Absent         int               `json:"absent"`
+	Excused        int               `json:"excused"`
+	AttendanceRate float64           `json:"attendance_rate"`
+}
+
+// AnalyticsResult 分析结果
+type AnalyticsResult struct {
+	Dimensions []string       `json:"dimensions"`
+	Rows       []AnalyticsRow `json:"rows"`
+}
+
+// analyticsSession 参与统计的会话及其课程、教师信息和签到人数
+type analyticsSession struct {
+	ID          uint
+	CourseID    uint
+	CourseName  string
+	Semester    string
+	TeacherID   uint
+	TeacherName string
+	StartTime   time.Time
+	Enrolled    int
+	Present     int
+	Late        int
+	Excused     int
+}
+
+var weekdayLabels = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// ParseAnalyticsDimensions 解析逗号分隔的分组维度
+func ParseAnalyticsDimensions(groupBy string) ([]string, error) {
+	dimensions := make([]string, 0)
+	if strings.TrimSpace(groupBy) == "" {
+		return dimensions, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, dimension := range strings.Split(groupBy, ",") {
+		dimension = strings.TrimSpace(dimension)
+		valid := false
+		for _, supported := range AnalyticsDimensions {
+			if dimension == supported {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, errors.New("不支持的分组维度: " + dimension)
+		}
+		if !seen[dimension] {
+			seen[dimension] = true
+			dimensions = append(dimensions, dimension)
+		}
+	}
+	return dimensions, nil
+}
+
+// dimensionID 教师和课程维度按 ID 分组，其他维度返回 0
+func (s analyticsSession) dimensionID(dimension string) uint {
+	switch dimension {
+	case "teacher":
+		return s.TeacherID
+	case "course":
+		return s.CourseID
+	}
+	return 0
+}
+
+// dimensionValue 获取会话在某个维度上的显示名称
+// 星期和时段在内存中计算，避免依赖特定数据库的日期函数
+func (s analyticsSession) dimensionValue(dimension string) string {
+	switch dimension {
+	case "semester":
+		return s.Semester
+	case "teacher":
+		return s.TeacherName
+	case "course":
+		return s.CourseName
+	case "weekday":
+		return weekdayLabels[s.StartTime.Weekday()]
+	case "time_slot":
+		hour := s.StartTime.Hour()
+		return fmt.Sprintf("%02d:00-%02d:00", hour, hour+1)
+	}
+	return ""
+}
+
+// Aggregate 按过滤条件统计已结束的签到会话，并按给定维度分组
+// 每个会话的应到人数和各状态人数在数据库中分组统计，避免按会话 ID 列表查询
+func (as *AnalyticsService) Aggregate(filter AnalyticsFilter, dimensions []string) (*AnalyticsResult, error) {
+	enrolled := database.DB.Model(&model.Enrollment{}).
+		Select("course_id, COUNT(*) AS enrolled").
+		Group("course_id")
+
+	var sessions []analyticsSession
+	query := database.DB.Model(&model.CheckinSession{}).
+		Select("checkin_sessions.id, checkin_sessions.course_id, courses.name AS course_name, courses.semester, courses.teacher_id, users.name AS teacher_name, checkin_sessions.start_time, "+
+			"COALESCE(enrolled_counts.enrolled, 0) AS enrolled, "+
+			"SUM(CASE WHEN checkin_records.status = 'present' THEN 1 ELSE 0 END) AS present, "+
+			"SUM(CASE WHEN checkin_records.status = 'late' THEN 1 ELSE 0 END) AS late, "+
+			"SUM(CASE WHEN checkin_records.status = 'excused' THEN 1 ELSE 0 END) AS excused").
+		Joins("JOIN courses ON courses.id = checkin_sessions.course_id").
+		Joins("LEFT JOIN users ON users.id = courses.teacher_id").
+		Joins("LEFT JOIN (?) AS enrolled_counts ON enrolled_counts.course_id = checkin_sessions.course_id", enrolled).
+		Joins("LEFT JOIN checkin_records ON checkin_records.session_id = checkin_sessions.id AND checkin_records.deleted_at IS NULL").
+		Where("checkin_sessions.status = ?", "ended").
+		Group("checkin_sessions.id, checkin_sessions.course_id, courses.name, courses.semester, courses.teacher_id, users.name, checkin_sessions.start_time, enrolled_counts.enrolled")
+	if filter.Semester != "" {
+		query = query.Where("courses.semester = ?", filter.Semester)
+	}
+	if filter.TeacherID != 0 {
+		query = query.Where("courses.teacher_id = ?", filter.TeacherID)
+	}
+	if filter.CourseID != 0 {
+		query = query.Where("checkin_sessions.course_id = ?", filter.CourseID)
+	}
+	if err := filter.DateRange.apply(query, "checkin_sessions.start_time").Scan(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	result := &AnalyticsResult{Dimensions: dimensions, Rows: make([]AnalyticsRow, 0)}
+	index := make(map[string]int)
+	for _, session := range sessions {
+		keys := make(map[string]string, len(dimensions))
+		var ids map[string]uint
+		parts := make([]string, 0, len(dimensions))
+		for _, dimension := range dimensions {
+			value := session.dimensionValue(dimension)
+			keys[dimension] = value
+			if id := session.dimensionID(dimension); id != 0 {
+				if ids == nil {
+					ids = make(map[string]uint)
+				}
+				ids[dimension] = id
+				value = fmt.Sprint(id)
+			}
+			parts = append(parts, value)
+		}
+		groupKey := strings.Join(parts, "\x00")
+
+		i, exists := index[groupKey]
+		if !exists {
+			result.Rows = append(result.Rows, AnalyticsRow{Keys: keys, IDs: ids})
+			i = len(result.Rows) - 1
+			index[groupKey] = i
+		}
+
+		row := &result.Rows[i]
+		row.Sessions++
+		row.Expected += session.Enrolled
+		row.Present += session.Present
+		row.Late += session.Late
+		row.Excused += session.Excused
+		// 未签到的选课学生计为缺勤
+		absent := session.Enrolled - session.Present - session.Late - session.Excused
+		if absent > 0 {
+			row.Absent += absent
+		}
+	}
+
+	for i := range result.Rows {
+		row := &result.Rows[i]
+		row.AttendanceRate = attendanceRate(row.Present, row.Late, row.Excused, row.Expected)
+	}
+
+	// 按维度取值排序，便于图表展示
+	sort.Slice(result.Rows, func(i, j int) bool {
+		for _, dimension := range dimensions {
+			a, b := result.Rows[i].Keys[dimension], result.Rows[j].Keys[dimension]
+			if dimension == "weekday" {
+				a, b = weekdayOrder(a), weekdayOrder(b)
+			}
+			if a != b {
+				return a < b
+			}
+		}
+		// 同名的教师或课程按 ID 排序，保证顺序稳定
+		for _, dimension := range dimensions {
+			if a, b := result.Rows[i].IDs[dimension], result.Rows[j].IDs[dimension]; a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+
+	return result, nil
+}
+
+// weekdayOrder 将星期名称转换为可排序的值（周一在前）
+func weekdayOrder(label string) string {
+	for i, weekday := range weekdayLabels {
+		if weekday == label {
+			return fmt.Sprint((i + 6) % 7)
+		}
+	}
+	return label
+}
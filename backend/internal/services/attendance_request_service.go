@@ -0,0 +1,141 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AttendanceRequestService 请假与考勤申诉服务
+type AttendanceRequestService struct{}
+
+// requestApprovedStatus 申请通过后写入签到记录的状态
+var requestApprovedStatus = map[string]string{
+	"leave":  "excused",
+	"appeal": "present",
+}
+
+// CreateRequest 学生针对某次签到提交请假或申诉
+func (s *AttendanceRequestService) CreateRequest(studentID uint, requestType string, sessionID uint, reason string) (*model.AttendanceRequest, error) {
+	if _, ok := requestApprovedStatus[requestType]; !ok {
+		return nil, errors.New("无效的申请类型")
+	}
+
+	var session model.CheckinSession
+	if err := database.DB.First(&session, sessionID).Error; err != nil {
+		return nil, errors.New("签到会话不存在")
+	}
+
+	var enrollment model.Enrollment
+	if err := database.DB.Where("student_id = ? AND course_id = ?", studentID, session.CourseID).First(&enrollment).Error; err != nil {
+		return nil, errors.New("您未选修该课程")
+	}
+
+	var count int64
+	database.DB.Model(&model.AttendanceRequest{}).Where("student_id = ? AND session_id = ? AND status = ?", studentID, sessionID, "pending").Count(&count)
+	if count > 0 {
+		return nil, errors.New("该签到已有待审核的申请")
+	}
+
+	request := model.AttendanceRequest{
+		Type:      requestType,
+		StudentID: studentID,
+		CourseID:  session.CourseID,
+		SessionID: sessionID,
+		Reason:    reason,
+		Status:    "pending",
+	}
+	if err := database.DB.Create(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetStudentRequests 获取学生本人的申请，status 为空时返回全部
+func (s *AttendanceRequestService) GetStudentRequests(studentID uint, status string) ([]model.AttendanceRequest, error) {
+	var requests []model.AttendanceRequest
+	query := database.DB.Preload("Course").Preload("Session").Where("student_id = ?", studentID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// GetReviewableRequests 获取当前用户可审核的申请：教师为其课程，助教为被分配课程，管理员不限制
+func (s *AttendanceRequestService) GetReviewableRequests(userID uint, role string, status string) ([]model.AttendanceRequest, error) {
+	var requests []model.AttendanceRequest
+	query := database.DB.Preload("Course").Preload("Session").Preload("Student")
+
+	switch role {
+	case "admin":
+	case "teacher":
+		query = query.Where("course_id IN (?)", database.DB.Model(&model.Course{}).Select("id").Where("teacher_id = ?", userID))
+	case "assistant":
+		query = query.Where("course_id IN (?)", database.DB.Model(&model.CourseAssistant{}).Select("course_id").Where("assistant_id = ?", userID))
+	default:
+		return requests, nil
+	}
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// GetRequestByID 根据ID获取申请
+func (s *AttendanceRequestService) GetRequestByID(id uint) (*model.AttendanceRequest, error) {
+	var request model.AttendanceRequest
+	if err := database.DB.First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("申请不存在")
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// ReviewRequest 审核申请，通过时将对应签到记录更新为请假或出勤
+func (s *AttendanceRequestService) ReviewRequest(id, reviewerID uint, approve bool, comment string) (*model.AttendanceRequest, error) {
+	request, err := s.GetRequestByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != "pending" {
+		return nil, errors.New("该申请已审核")
+	}
+
+	if approve {
+		request.Status = "approved"
+	} else {
+		request.Status = "rejected"
+	}
+	now := time.Now()
+	request.ReviewerID = &reviewerID
+	request.ReviewComment = comment
+	request.ReviewedAt = &now
+
+	// 补签和审核结果在同一事务中提交，避免签到记录已修改而申请仍为待审核
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(request).Where("status = ?", "pending").
+			Select("status", "reviewer_id", "review_comment", "reviewed_at").Updates(request)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("该申请已审核")
+		}
+		if approve {
+			return manualCheckin(tx, request.SessionID, request.StudentID, requestApprovedStatus[request.Type])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
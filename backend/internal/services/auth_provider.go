@@ -0,0 +1,258 @@
+package services
+
+import (
+	"backend/config"
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/utils"
+	"errors"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 账号来源
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+	AuthSourceOIDC  = "oidc"
+)
+
+var (
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	// ErrUnknownAccount 账号不由该认证方式管理，交给下一个认证方式处理
+	ErrUnknownAccount = errors.New("账号不存在")
+	// ErrExternalAccount 统一身份认证账号的密码由目录管理，不能在本系统修改
+	ErrExternalAccount = errors.New("该账号使用统一身份认证登录，请在统一身份认证平台修改密码")
+)
+
+// AuthProvider 用户名密码认证方式
+type AuthProvider interface {
+	// Name 认证方式名称，与 User.AuthSource 对应
+	Name() string
+	// Authenticate 校验用户名和密码并返回对应的用户，账号不由该认证方式管理时返回 ErrUnknownAccount
+	Authenticate(username, password string) (*model.User, error)
+}
+
+// ExternalProfile 外部身份源返回的用户信息
+type ExternalProfile struct {
+	Username string
+	Name     string
+	Email    string
+	Role     string
+}
+
+// LocalAuthProvider 使用 User.PasswordHash 校验密码
+type LocalAuthProvider struct{}
+
+// Name 认证方式名称
+func (p *LocalAuthProvider) Name() string {
+	return AuthSourceLocal
+}
+
+// Authenticate 校验本地密码，非本地账号交给其他认证方式
+func (p *LocalAuthProvider) Authenticate(username, password string) (*model.User, error) {
+	var user model.User
+	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnknownAccount
+		}
+		return nil, err
+	}
+	if user.AuthSource != AuthSourceLocal {
+		return nil, ErrUnknownAccount
+	}
+	if !utils.CheckPasswordHash(password, user.PasswordHash) {
+		return nil, ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+// authProviders 按配置顺序返回启用的认证方式，未知名称会被忽略
+func authProviders() []AuthProvider {
+	var providers []AuthProvider
+	for _, name := range strings.Split(config.Cfg.AuthProviders, ",") {
+		switch strings.TrimSpace(name) {
+		case AuthSourceLocal:
+			providers = append(providers, &LocalAuthProvider{})
+		case AuthSourceLDAP:
+			providers = append(providers, NewLDAPAuthProvider(config.Cfg))
+		case "":
+		default:
+			log.Printf("未知的认证方式: %s", name)
+		}
+	}
+	if len(providers) == 0 {
+		providers = append(providers, &LocalAuthProvider{})
+	}
+	return providers
+}
+
+// Authenticate 依次尝试启用的认证方式，第一个管理该账号的认证方式给出结果
+func (s *AuthService) Authenticate(username, password string) (*model.User, error) {
+	for _, provider := range s.getProviders() {
+		user, err := provider.Authenticate(username, password)
+		if errors.Is(err, ErrUnknownAccount) {
+			continue
+		}
+		return user, err
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// CheckPassword 使用账号所属的认证方式重新校验密码，用于敏感操作前确认身份
+func (s *AuthService) CheckPassword(user *model.User, password string) error {
+	for _, provider := range s.getProviders() {
+		if provider.Name() != user.AuthSource {
+			continue
+		}
+		verified, err := provider.Authenticate(user.Username, password)
+		if err != nil {
+			if errors.Is(err, ErrUnknownAccount) {
+				return ErrInvalidCredentials
+			}
+			return err
+		}
+		if verified.ID != user.ID {
+			return ErrInvalidCredentials
+		}
+		return nil
+	}
+	return ErrInvalidCredentials
+}
+
+// getProviders 未指定认证方式时使用配置中的认证方式
+func (s *AuthService) getProviders() []AuthProvider {
+	if s.providers == nil {
+		s.providers = authProviders()
+	}
+	return s.providers
+}
+
+// provisionExternalUser 外部身份源认证通过后查找或创建本地用户（即时开通）
+// 已存在的账号每次登录同步姓名、邮箱和角色，角色变化时吊销已签发的令牌，管理员角色不被覆盖；
+// linkLocal 为 true 时接管同名的非管理员本地账号
+// 已删除的账号仍占用用户名，不重新开通也不恢复
+func provisionExternalUser(source string, profile *ExternalProfile, linkLocal bool) (*model.User, error) {
+	var user model.User
+	err := database.DB.Unscoped().Where("username = ?", profile.Username).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return createExternalUser(database.DB, source, profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletedAt.Valid {
+		log.Printf("%s 用户 %s 对应的本地账号已删除，拒绝登录", source, user.Username)
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.AuthSource != source {
+		if user.AuthSource != AuthSourceLocal || !linkLocal || user.Role == "admin" {
+			return nil, ErrUnknownAccount
+		}
+		log.Printf("本地用户 %s 已关联到 %s", user.Username, source)
+	}
+
+	updates := map[string]interface{}{"auth_source": source}
+	if profile.Name != "" {
+		updates["name"] = profile.Name
+	}
+	if profile.Email != "" {
+		updates["email"] = profile.Email
+	}
+	roleChanged := profile.Role != "" && user.Role != "admin" && user.Role != profile.Role
+	if roleChanged {
+		log.Printf("%s 用户 %s 的角色由 %s 变为 %s", source, user.Username, user.Role, profile.Role)
+		updates["role"] = profile.Role
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if !roleChanged {
+			return nil
+		}
+		// 旧令牌中携带原角色，必须失效；重新读取用户以取得新的令牌版本
+		if err := revokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.First(&user, user.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// createExternalUser 为外部身份源认证通过的新用户开通账号，无法确定角色时拒绝登录
+func createExternalUser(tx *gorm.DB, source string, profile *ExternalProfile) (*model.User, error) {
+	if profile.Role == "" {
+		return nil, ErrInvalidCredentials
+	}
+	user := model.User{
+		Username:   profile.Username,
+		Name:       profile.Name,
+		Email:      profile.Email,
+		Role:       profile.Role,
+		AuthSource: source,
+	}
+	if user.Name == "" {
+		user.Name = profile.Username
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	log.Printf("已通过 %s 开通用户 %s（%s）", source, user.Username, user.Role)
+	return &user, nil
+}
+
+// 外部身份源映射角色时按此顺序取优先级最高的一个，管理员权限只能在系统内授予
+var externalRolePriority = []string{"teacher", "assistant", "student"}
+
+// isExternalRole 判断是否为可由外部身份源授予的角色
+func isExternalRole(role string) bool {
+	for _, r := range externalRolePriority {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRoleMap 解析 faculty=teacher,student=student 形式的角色映射，键不区分大小写
+// 映射到 admin 等无效角色的配置会被忽略
+func parseRoleMap(s string) map[string]string {
+	roleMap := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value, role := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		if !isExternalRole(role) {
+			log.Printf("角色映射 %s 无效，已忽略", pair)
+			continue
+		}
+		roleMap[value] = role
+	}
+	return roleMap
+}
+
+// mapExternalRole 将外部身份源的角色值映射为系统角色，多个值时取优先级最高的角色，无法映射时返回空
+func mapExternalRole(roleMap map[string]string, values []string) string {
+	mapped := make(map[string]bool)
+	for _, value := range values {
+		if role, ok := roleMap[strings.ToLower(value)]; ok {
+			mapped[role] = true
+		}
+	}
+	for _, role := range externalRolePriority {
+		if mapped[role] {
+			return role
+		}
+	}
+	return ""
+}
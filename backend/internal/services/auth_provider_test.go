@@ -0,0 +1,113 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRoleMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{"空配置", "", map[string]string{}},
+		{"多个映射", "faculty=teacher,staff=assistant,student=student",
+			map[string]string{"faculty": "teacher", "staff": "assistant", "student": "student"}},
+		{"键转为小写并去除空白", " Faculty = teacher ", map[string]string{"faculty": "teacher"}},
+		{"忽略管理员和无效角色", "root=admin,guest=visitor,student=student", map[string]string{"student": "student"}},
+		{"忽略缺少等号的项", "faculty,student=student", map[string]string{"student": "student"}},
+		{"按第一个等号拆分", "cn=teachers=teacher", map[string]string{}},
+		{"重复的键以后者为准", "staff=assistant,staff=teacher", map[string]string{"staff": "teacher"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRoleMap(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRoleMap(%q) = %v，期望 %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapExternalRole(t *testing.T) {
+	roleMap := parseRoleMap("faculty=teacher,staff=assistant,student=student")
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{"没有角色值", nil, ""},
+		{"无法映射", []string{"alumni"}, ""},
+		{"单个角色", []string{"student"}, "student"},
+		{"不区分大小写", []string{"FACULTY"}, "teacher"},
+		{"多个角色取优先级最高的", []string{"student", "staff"}, "assistant"},
+		{"教师优先于助教", []string{"staff", "faculty", "student"}, "teacher"},
+		{"忽略无法映射的值", []string{"alumni", "student"}, "student"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapExternalRole(roleMap, tt.values); got != tt.want {
+				t.Errorf("mapExternalRole(%v) = %q，期望 %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvisionExternalUserRejectsDeletedUser(t *testing.T) {
+	setupTestDB(t, &model.User{})
+
+	user := model.User{Username: "alice", Name: "Alice", Role: "student", AuthSource: AuthSourceLDAP}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	if err := database.DB.Delete(&user).Error; err != nil {
+		t.Fatalf("删除用户失败: %v", err)
+	}
+
+	profile := &ExternalProfile{Username: "alice", Name: "Alice", Role: "student"}
+	if _, err := provisionExternalUser(AuthSourceLDAP, profile, false); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("已删除用户登录应返回 ErrInvalidCredentials，实际为 %v", err)
+	}
+
+	var count int64
+	database.DB.Unscoped().Model(&model.User{}).Where("username = ?", "alice").Count(&count)
+	if count != 1 {
+		t.Errorf("不应重新开通已删除的用户，实际共有 %d 条记录", count)
+	}
+}
+
+func TestProvisionExternalUserSyncsRole(t *testing.T) {
+	setupTestDB(t, &model.User{}, &model.RefreshToken{})
+
+	teacher := model.User{Username: "bob", Name: "Bob", Role: "teacher", AuthSource: AuthSourceLDAP}
+	admin := model.User{Username: "root", Name: "Root", Role: "admin", AuthSource: AuthSourceLDAP}
+	for _, user := range []*model.User{&teacher, &admin} {
+		if err := database.DB.Create(user).Error; err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+	}
+
+	user, err := provisionExternalUser(AuthSourceLDAP, &ExternalProfile{Username: "bob", Role: "student"}, false)
+	if err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if user.Role != "student" {
+		t.Errorf("角色为 %s，期望同步为 student", user.Role)
+	}
+	if user.TokenVersion != teacher.TokenVersion+1 {
+		t.Errorf("令牌版本为 %d，角色变化后应吊销旧令牌", user.TokenVersion)
+	}
+
+	user, err = provisionExternalUser(AuthSourceLDAP, &ExternalProfile{Username: "root", Role: "teacher"}, false)
+	if err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if user.Role != "admin" {
+		t.Errorf("管理员角色被覆盖为 %s", user.Role)
+	}
+}
@@ -1 +1,157 @@
 package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/utils"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenTTL 刷新令牌有效期
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// ErrInvalidRefreshToken 刷新令牌不存在、已过期或已被吊销
+var ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期，请重新登录")
+
+// AuthService 登录认证服务：按配置的认证方式校验身份，签发短期访问令牌和可轮换的刷新令牌，并负责吊销
+type AuthService struct {
+	providers []AuthProvider
+}
+
+// TokenPair 登录或刷新后返回给客户端的令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // 访问令牌有效秒数
+}
+
+// IssueTokens 为用户签发访问令牌和新的刷新令牌
+func (as *AuthService) IssueTokens(user *model.User) (*TokenPair, error) {
+	return issueTokens(database.DB, user)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧刷新令牌随即失效
+// 已吊销的刷新令牌被再次使用时视为令牌泄露，吊销该用户的全部令牌
+func (as *AuthService) Refresh(refreshToken string) (*TokenPair, *model.User, error) {
+	var stored model.RefreshToken
+	if err := database.DB.Where("token_hash = ?", hashToken(refreshToken)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		log.Printf("用户 %d 的刷新令牌 %d 在吊销后被再次使用，吊销该用户全部令牌", stored.UserID, stored.ID)
+		if err := as.RevokeUserTokens(stored.UserID); err != nil {
+			log.Printf("吊销用户 %d 的令牌失败: %v", stored.UserID, err)
+		}
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	// 已删除的用户查询不到，无法继续刷新
+	var user model.User
+	if err := database.DB.First(&user, stored.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	var pair *TokenPair
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 条件更新保证同一个刷新令牌只能成功轮换一次
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", stored.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidRefreshToken
+		}
+
+		var err error
+		pair, err = issueTokens(tx, &user)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, &user, nil
+}
+
+// Logout 吊销客户端持有的刷新令牌，不存在的令牌直接忽略
+func (as *AuthService) Logout(refreshToken string) error {
+	return database.DB.Model(&model.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hashToken(refreshToken)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserTokens 吊销用户已签发的全部访问令牌和刷新令牌
+func (as *AuthService) RevokeUserTokens(userID uint) error {
+	return revokeUserTokens(database.DB, userID)
+}
+
+// revokeUserTokens 递增用户的令牌版本并吊销其全部刷新令牌，可在事务中调用
+func revokeUserTokens(tx *gorm.DB, userID uint) error {
+	// 用户可能已被软删除，使用 Unscoped 保证版本号仍然递增
+	if err := tx.Unscoped().Model(&model.User{}).Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// issueTokens 签发访问令牌并保存新的刷新令牌
+func issueTokens(tx *gorm.DB, user *model.User) (*TokenPair, error) {
+	accessToken, err := utils.GenerateJWT(user.ID, user.Role, user.TokenVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&model.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// generateRandomToken 生成 32 字节的随机令牌（十六进制编码）
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken 计算令牌的 SHA-256 哈希，数据库中只保存哈希值
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"errors"
+)
+
+// ErrForbidden 当前用户无权操作该资源
+var ErrForbidden = errors.New("无权操作该资源")
+
+// AuthorizationService 资源归属校验
+// 管理员不受限制；教师只能访问本人任课课程及其签到会话、记录；助教只能访问被分配课程的签到数据
+// 资源不存在时返回 gorm.ErrRecordNotFound，无权访问时返回 ErrForbidden
+type AuthorizationService struct {
+	courseService CourseService
+}
+
+// AuthorizeCourse 校验用户可以访问课程的签到数据（课程教师或课程助教）
+func (as *AuthorizationService) AuthorizeCourse(userID uint, role string, courseID uint) (*model.Course, error) {
+	course, err := as.courseService.GetCourseByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "admin" || (role == "teacher" && course.TeacherID == userID) {
+		return course, nil
+	}
+	if role == "assistant" && as.courseService.IsCourseAssistant(userID, courseID) {
+		return course, nil
+	}
+	return nil, ErrForbidden
+}
+
+// AuthorizeCourseOwner 校验用户可以管理课程本身（修改、删除、选课和助教分配），仅课程教师和管理员
+func (as *AuthorizationService) AuthorizeCourseOwner(userID uint, role string, courseID uint) (*model.Course, error) {
+	course, err := as.courseService.GetCourseByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "admin" || (role == "teacher" && course.TeacherID == userID) {
+		return course, nil
+	}
+	return nil, ErrForbidden
+}
+
+// AuthorizeSession 校验用户可以操作签到会话及其签到记录，会话发起教师也可访问
+func (as *AuthorizationService) AuthorizeSession(userID uint, role string, sessionID uint) (*model.CheckinSession, error) {
+	var session model.CheckinSession
+	if err := database.DB.First(&session, sessionID).Error; err != nil {
+		return nil, err
+	}
+	if role == "teacher" && session.TeacherID == userID {
+		return &session, nil
+	}
+	if _, err := as.AuthorizeCourse(userID, role, session.CourseID); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// AuthorizeEnrollment 校验用户可以管理选课记录，仅课程教师和管理员
+func (as *AuthorizationService) AuthorizeEnrollment(userID uint, role string, enrollmentID uint) (*model.Enrollment, error) {
+	var enrollment model.Enrollment
+	if err := database.DB.First(&enrollment, enrollmentID).Error; err != nil {
+		return nil, err
+	}
+	if _, err := as.AuthorizeCourseOwner(userID, role, enrollment.CourseID); err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
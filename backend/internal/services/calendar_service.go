@@ -0,0 +1,105 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/ical"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// calendarLookback 日历订阅中包含的历史签到会话天数
+const calendarLookback = 180 * 24 * time.Hour
+
+// CalendarService 个人签到日历订阅服务
+type CalendarService struct{}
+
+// GetOrCreateToken 获取用户的日历订阅令牌，不存在时生成
+func (cs *CalendarService) GetOrCreateToken(userID uint) (string, error) {
+	var feed model.CalendarFeed
+	err := database.DB.Where("user_id = ?", userID).First(&feed).Error
+	if err == nil {
+		return feed.Token, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	return cs.ResetToken(userID)
+}
+
+// ResetToken 重新生成用户的日历订阅令牌，旧的订阅地址随即失效
+func (cs *CalendarService) ResetToken(userID uint) (string, error) {
+	token, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	var feed model.CalendarFeed
+	err = database.DB.Where("user_id = ?", userID).First(&feed).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		feed = model.CalendarFeed{UserID: userID, Token: token}
+		return token, database.DB.Create(&feed).Error
+	}
+	if err != nil {
+		return "", err
+	}
+	return token, database.DB.Model(&feed).Update("token", token).Error
+}
+
+// GetUserByToken 根据订阅令牌查找用户
+func (cs *CalendarService) GetUserByToken(token string) (*model.User, error) {
+	var feed model.CalendarFeed
+	if err := database.DB.Preload("User").Where("token = ?", token).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	if feed.User.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &feed.User, nil
+}
+
+// BuildCalendar 生成用户所在课程的签到会话日历
+// 教师为其任课课程，学生为已选课程，助教为被分配的课程
+func (cs *CalendarService) BuildCalendar(user *model.User) (*ical.Calendar, error) {
+	var courseIDs interface{}
+	switch user.Role {
+	case "teacher":
+		courseIDs = database.DB.Model(&model.Course{}).Select("id").Where("teacher_id = ?", user.ID)
+	case "student":
+		courseIDs = database.DB.Model(&model.Enrollment{}).Select("course_id").Where("student_id = ?", user.ID)
+	case "assistant":
+		courseIDs = database.DB.Model(&model.CourseAssistant{}).Select("course_id").Where("assistant_id = ?", user.ID)
+	}
+
+	cal := &ical.Calendar{Name: user.Name + "的签到日历"}
+	if courseIDs == nil {
+		return cal, nil
+	}
+
+	var sessions []model.CheckinSession
+	if err := database.DB.Preload("Course").Preload("Teacher").
+		Where("course_id IN (?) AND start_time >= ?", courseIDs, time.Now().Add(-calendarLookback)).
+		Order("start_time").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		statusText := "已结束"
+		if session.Status == "active" {
+			statusText = "进行中"
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("checkin-session-%d@attendance", session.ID),
+			Start:       session.StartTime,
+			End:         session.StartTime.Add(time.Duration(session.Duration) * time.Minute),
+			Summary:     session.Course.Name + " 签到",
+			Description: fmt.Sprintf("课程编号：%s\n教师：%s\n状态：%s", session.Course.CourseCode, session.Teacher.Name, statusText),
+			Status:      "CONFIRMED",
+		})
+	}
+	return cal, nil
+}
@@ -5,6 +5,7 @@ import (
 	"backend/pkg/database"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -32,8 +33,8 @@ func CreateCheckinSession(teacherID, courseID uint, duration int) (string, error
 	return sessionCode, nil
 }
 
-// ProcessStudentCheckin 处理学生签到
-func ProcessStudentCheckin(sessionCode string, studentID uint) error {
+// ProcessStudentCheckin 处理学生签到，记录客户端IP和 User-Agent 供异常签到检测使用
+func ProcessStudentCheckin(sessionCode string, studentID uint, clientIP, userAgent string) error {
 	var session models.CheckinSession
 
 	// 查找活跃的会话
@@ -63,11 +64,13 @@ func ProcessStudentCheckin(sessionCode string, studentID uint) error {
 		CourseID:             session.CourseID,
 		CheckinTime:          time.Now(), // 添加这一行来设置正确的签到时间
 		Status:               "present",  // 默认为出勤
+		ClientIP:             clientIP,
+		UserAgent:            truncate(userAgent, 512),
 		UniqueSessionStudent: fmt.Sprintf("%d-%d", session.ID, studentID),
 	}
 
 	// 使用事务确保原子性
-	return database.DB.Transaction(func(tx *gorm.DB) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
 		// 尝试创建记录，利用唯一索引防止重复
 		if err := tx.Create(&record).Error; err != nil {
 			if isUniqueConstraintError(err) {
@@ -77,6 +80,14 @@ func ProcessStudentCheckin(sessionCode string, studentID uint) error {
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// 异步执行异常签到检测，不影响签到响应；短时间内的多次签到合并为一次检测
+	(&DetectionService{}).ScheduleEvaluation(session.ID)
+
+	return nil
 }
 
 // GetSessionDisplayInfo 获取会话展示信息
@@ -139,6 +150,7 @@ func GetCheckinRecordsBySession(sessionID uint) ([]gin.H, error) {
 			// 学生已签到
 			result = append(result, gin.H{
 				"student_id":   record.StudentID,
+				"username":     record.Student.Username,
 				"student_name": record.Student.Name,
 				"checkin_time": record.CheckinTime.Format("2006-01-02 15:04:05"),
 				"status":       record.Status,
@@ -147,6 +159,7 @@ func GetCheckinRecordsBySession(sessionID uint) ([]gin.H, error) {
 			// 学生未签到
 			result = append(result, gin.H{
 				"student_id":   enrollment.StudentID,
+				"username":     enrollment.Student.Username,
 				"student_name": enrollment.Student.Name,
 				"checkin_time": nil,
 				"status":       "absent", // 未签到的学生标记为缺席
@@ -182,14 +195,14 @@ func EndCheckinSession(sessionID uint) error {
 	return nil
 }
 
-// ManualEndCheckinSession 手动结束签到会话（教师强制结束）
-func ManualEndCheckinSession(sessionID uint, teacherID uint) error {
+// ManualEndCheckinSession 手动结束签到会话（调用方需先通过 AuthorizationService 校验会话归属）
+func ManualEndCheckinSession(sessionID uint) error {
 	var session models.CheckinSession
 	
-	// 查找会话并验证教师权限
-	if err := database.DB.Where("id = ? AND teacher_id = ?", sessionID, teacherID).First(&session).Error; err != nil {
+	// 查找会话
+	if err := database.DB.Where("id = ?", sessionID).First(&session).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("签到会话不存在或您无权限操作此会话")
+			return errors.New("签到会话不存在")
 		}
 		return errors.New("查询签到会话失败: " + err.Error())
 	}
@@ -202,7 +215,7 @@ func ManualEndCheckinSession(sessionID uint, teacherID uint) error {
 	// 更新会话状态为已结束
 	if err := database.DB.Model(&session).Updates(map[string]interface{}{
 		"status": "ended",
-		"duration": int(time.Since(session.StartTime).Minutes()), // 更新实际持续时间
+		"duration": int(math.Ceil(time.Since(session.StartTime).Minutes())), // 更新实际持续时间，不足一分钟按一分钟计，保证最后一分钟内的签到仍在窗口内
 	}).Error; err != nil {
 		return errors.New("手动结束签到会话失败: " + err.Error())
 	}
@@ -212,20 +225,25 @@ func ManualEndCheckinSession(sessionID uint, teacherID uint) error {
 
 // ManualCheckin 手动补签功能
 func ManualCheckin(sessionID uint, studentID uint, status string) error {
+	return manualCheckin(database.DB, sessionID, studentID, status)
+}
+
+// manualCheckin 补签或修改签到状态，db 可以是外层事务，使补签与调用方的其他修改一起提交或回滚
+func manualCheckin(db *gorm.DB, sessionID uint, studentID uint, status string) error {
 	// 验证状态值
-	if status != "present" && status != "late" && status != "absent" {
+	if status != "present" && status != "late" && status != "absent" && status != "excused" {
 		return errors.New("无效的状态值")
 	}
 
 	// 获取会话信息
 	var session models.CheckinSession
-	if err := database.DB.Where("id = ?", sessionID).First(&session).Error; err != nil {
+	if err := db.Where("id = ?", sessionID).First(&session).Error; err != nil {
 		return errors.New("签到会话不存在")
 	}
 
 	// 检查学生是否选修了该课程
 	var enrollment models.Enrollment
-	if err := database.DB.Where("student_id = ? AND course_id = ?", studentID, session.CourseID).First(&enrollment).Error; err != nil {
+	if err := db.Where("student_id = ? AND course_id = ?", studentID, session.CourseID).First(&enrollment).Error; err != nil {
 		return errors.New("该学生未选修此课程")
 	}
 
@@ -240,7 +258,7 @@ func ManualCheckin(sessionID uint, studentID uint, status string) error {
 	}
 
 	// 使用事务确保原子性
-	return database.DB.Transaction(func(tx *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
 		// 尝试创建记录，如果已存在则更新
 		if err := tx.Where("session_id = ? AND student_id = ?", session.ID, studentID).First(&record).Error; err != nil {
 			// 记录不存在，创建新记录
@@ -265,6 +283,17 @@ func ManualCheckin(sessionID uint, studentID uint, status string) error {
 	})
 }
 
+// ScopeSessionsByRole 按角色限制可见的签到会话：教师仅本人发起的会话，助教仅被分配课程的会话，管理员不限制
+func ScopeSessionsByRole(query *gorm.DB, userID uint, role string) *gorm.DB {
+	switch role {
+	case "teacher":
+		return query.Where("checkin_sessions.teacher_id = ?", userID)
+	case "assistant":
+		return query.Where("checkin_sessions.course_id IN (?)", database.DB.Model(&models.CourseAssistant{}).Select("course_id").Where("assistant_id = ?", userID))
+	}
+	return query
+}
+
 // isUniqueConstraintError 判断是否为 MySQL 唯一约束错误
 func isUniqueConstraintError(err error) bool {
 	if err != nil {
@@ -273,4 +302,12 @@ func isUniqueConstraintError(err error) bool {
 		return (errStr != "" && len(errStr) > 10 && errStr[0:10] == "Error 1062")
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// truncate 按字节截断字符串，保证不超过数据库字段长度
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -3,15 +3,40 @@ package services
 import (
 	model "backend/internal/model"
 	"backend/pkg/database"
+	"errors"
+
+	"gorm.io/gorm"
 )
 
 type CourseService struct{}
 
-// GetCourses 获取所有课程
-func (cs *CourseService) GetCourses() ([]model.Course, error) {
+// CourseListSpec 课程列表支持的排序和筛选参数
+var CourseListSpec = ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":          "id",
+		"course_code": "course_code",
+		"name":        "name",
+		"semester":    "semester",
+		"credit":      "credit",
+		"created_at":  "created_at",
+	},
+	Filters: map[string]string{
+		"semester":   "semester = ?",
+		"teacher_id": "teacher_id = ?",
+	},
+	KeywordColumns: []string{"name", "course_code"},
+}
+
+// ListCourses 按条件查询课程（含教师信息），分页时同时返回总数
+func (cs *CourseService) ListCourses(q *ListQuery) ([]model.Course, int64, error) {
+	query, total, err := q.Apply(database.DB.Model(&model.Course{}))
+	if err != nil {
+		return nil, 0, err
+	}
 	var courses []model.Course
-	result := database.DB.Find(&courses)
-	return courses, result.Error
+	err = query.Preload("Teacher").Find(&courses).Error
+	return courses, total, err
 }
 
 // GetCourseByID 根据ID获取课程
@@ -30,3 +55,73 @@ func (cs *CourseService) GetCoursesByTeacherID(teacherID uint) ([]model.Course,
 	result := database.DB.Where("teacher_id = ?", teacherID).Find(&courses)
 	return courses, result.Error
 }
+
+// GetCoursesByAssistantID 获取助教被分配的课程
+func (cs *CourseService) GetCoursesByAssistantID(assistantID uint) ([]model.Course, error) {
+	var courses []model.Course
+	result := database.DB.Preload("Teacher").
+		Joins("JOIN course_assistants ON course_assistants.course_id = courses.id AND course_assistants.deleted_at IS NULL").
+		Where("course_assistants.assistant_id = ?", assistantID).
+		Find(&courses)
+	return courses, result.Error
+}
+
+// GetAssistants 获取课程的助教列表
+func (cs *CourseService) GetAssistants(courseID uint) ([]model.CourseAssistant, error) {
+	var assistants []model.CourseAssistant
+	result := database.DB.Preload("Assistant").Where("course_id = ?", courseID).Find(&assistants)
+	return assistants, result.Error
+}
+
+// AssignAssistant 为课程分配助教
+func (cs *CourseService) AssignAssistant(courseID, assistantID uint) (*model.CourseAssistant, error) {
+	if _, err := cs.GetCourseByID(courseID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("课程不存在")
+		}
+		return nil, err
+	}
+
+	var user model.User
+	if err := database.DB.Where("id = ? AND role = ?", assistantID, "assistant").First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("助教用户不存在")
+		}
+		return nil, err
+	}
+
+	if cs.IsCourseAssistant(assistantID, courseID) {
+		return nil, errors.New("该助教已分配到此课程")
+	}
+
+	assistant := model.CourseAssistant{
+		CourseID:    courseID,
+		AssistantID: assistantID,
+	}
+	if err := database.DB.Create(&assistant).Error; err != nil {
+		return nil, err
+	}
+
+	assistant.Assistant = user
+	return &assistant, nil
+}
+
+// RemoveAssistant 取消课程的助教分配
+func (cs *CourseService) RemoveAssistant(courseID, assistantID uint) error {
+	// 物理删除，避免软删除记录占用唯一索引导致无法再次分配
+	result := database.DB.Unscoped().Where("course_id = ? AND assistant_id = ?", courseID, assistantID).Delete(&model.CourseAssistant{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该助教未分配到此课程")
+	}
+	return nil
+}
+
+// IsCourseAssistant 判断用户是否为课程的助教
+func (cs *CourseService) IsCourseAssistant(userID, courseID uint) bool {
+	var count int64
+	database.DB.Model(&model.CourseAssistant{}).Where("course_id = ? AND assistant_id = ?", courseID, userID).Count(&count)
+	return count > 0
+}
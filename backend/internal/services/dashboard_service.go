@@ -0,0 +1,254 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"sort"
+	"time"
+)
+
+// DashboardService 教师首页看板服务，使用分组统计查询避免逐个会话加载记录
+type DashboardService struct {
+	requestService AttendanceRequestService
+}
+
+// DashboardSession 看板中的签到会话
+type DashboardSession struct {
+	ID          uint      `json:"id"`
+	SessionCode string    `json:"session_code"`
+	CourseID    uint      `json:"course_id"`
+	CourseName  string    `json:"course_name"`
+	StartTime   time.Time `json:"start_time"`
+	Duration    int       `json:"duration"`
+	Status      string    `json:"status"`
+	Enrolled    int       `json:"enrolled"`
+	CheckedIn   int       `json:"checked_in"`
+}
+
+// DashboardCourseRate 课程近期出勤率
+type DashboardCourseRate struct {
+	CourseID       uint    `json:"course_id"`
+	CourseName     string  `json:"course_name"`
+	Sessions       int     `json:"sessions"`
+	Enrolled       int     `json:"enrolled"`
+	AttendanceRate float64 `json:"attendance_rate"`
+}
+
+// TeacherDashboard 教师首页看板数据
+type TeacherDashboard struct {
+	TodaySessions       []DashboardSession        `json:"today_sessions"`
+	ActiveSessions      []DashboardSession        `json:"active_sessions"`
+	LowAttendance       []DashboardCourseRate     `json:"low_attendance_courses"`
+	PendingRequestCount int                       `json:"pending_request_count"`
+	PendingRequests     []model.AttendanceRequest `json:"-"`
+}
+
+const (
+	// dashboardRecentDays 统计近期出勤率的天数
+	dashboardRecentDays = 30
+	// dashboardLowCourseLimit 返回出勤率最低的课程数量
+	dashboardLowCourseLimit = 5
+)
+
+// courseCount 按课程分组的计数
+type courseCount struct {
+	CourseID uint
+	Count    int
+}
+
+// courseStatusCount 按课程和状态分组的计数
+type courseStatusCount struct {
+	CourseID uint
+	Status   string
+	Count    int
+}
+
+// GetTeacherDashboard 获取当前教师（或助教、管理员）的首页看板
+func (ds *DashboardService) GetTeacherDashboard(userID uint, role string) (*TeacherDashboard, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	todaySessions, err := ds.querySessions(userID, role, "checkin_sessions.start_time >= ? AND checkin_sessions.start_time < ?", today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	activeSessions, err := ds.querySessions(userID, role, "checkin_sessions.status = ?", "active")
+	if err != nil {
+		return nil, err
+	}
+	if err := ds.fillLiveCounts(todaySessions, activeSessions); err != nil {
+		return nil, err
+	}
+
+	lowAttendance, err := ds.lowAttendanceCourses(userID, role, now.AddDate(0, 0, -dashboardRecentDays))
+	if err != nil {
+		return nil, err
+	}
+
+	pendingRequests, err := ds.requestService.GetReviewableRequests(userID, role, "pending")
+	if err != nil {
+		return nil, err
+	}
+
+	return &TeacherDashboard{
+		TodaySessions:       todaySessions,
+		ActiveSessions:      activeSessions,
+		LowAttendance:       lowAttendance,
+		PendingRequestCount: len(pendingRequests),
+		PendingRequests:     pendingRequests,
+	}, nil
+}
+
+// querySessions 按角色范围查询签到会话，并通过连表获取课程名称
+func (ds *DashboardService) querySessions(userID uint, role string, condition string, args ...interface{}) ([]DashboardSession, error) {
+	sessions := make([]DashboardSession, 0)
+	query := database.DB.Model(&model.CheckinSession{}).
+		Select("checkin_sessions.id, checkin_sessions.session_code, checkin_sessions.course_id, courses.name AS course_name, checkin_sessions.start_time, checkin_sessions.duration, checkin_sessions.status").
+		Joins("JOIN courses ON courses.id = checkin_sessions.course_id").
+		Where(condition, args...)
+	err := ScopeSessionsByRole(query, userID, role).Order("checkin_sessions.start_time").Scan(&sessions).Error
+	return sessions, err
+}
+
+// fillLiveCounts 用两次分组查询填充会话的应到人数和已签到人数
+func (ds *DashboardService) fillLiveCounts(groups ...[]DashboardSession) error {
+	sessionIDs := make([]uint, 0)
+	courseIDs := make([]uint, 0)
+	for _, sessions := range groups {
+		for _, session := range sessions {
+			sessionIDs = append(sessionIDs, session.ID)
+			courseIDs = append(courseIDs, session.CourseID)
+		}
+	}
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	var enrolledCounts []courseCount
+	if err := database.DB.Model(&model.Enrollment{}).
+		Select("course_id, COUNT(*) AS count").
+		Where("course_id IN ?", courseIDs).
+		Group("course_id").
+		Scan(&enrolledCounts).Error; err != nil {
+		return err
+	}
+	enrolledMap := make(map[uint]int, len(enrolledCounts))
+	for _, count := range enrolledCounts {
+		enrolledMap[count.CourseID] = count.Count
+	}
+
+	var checkedInCounts []struct {
+		SessionID uint
+		Count     int
+	}
+	if err := database.DB.Model(&model.CheckinRecord{}).
+		Select("session_id, COUNT(*) AS count").
+		Where("session_id IN ? AND status IN ?", sessionIDs, []string{"present", "late"}).
+		Group("session_id").
+		Scan(&checkedInCounts).Error; err != nil {
+		return err
+	}
+	checkedInMap := make(map[uint]int, len(checkedInCounts))
+	for _, count := range checkedInCounts {
+		checkedInMap[count.SessionID] = count.Count
+	}
+
+	for _, sessions := range groups {
+		for i := range sessions {
+			sessions[i].Enrolled = enrolledMap[sessions[i].CourseID]
+			sessions[i].CheckedIn = checkedInMap[sessions[i].ID]
+		}
+	}
+	return nil
+}
+
+// lowAttendanceCourses 统计 since 之后已结束会话的课程出勤率，返回出勤率最低的几门课程
+func (ds *DashboardService) lowAttendanceCourses(userID uint, role string, since time.Time) ([]DashboardCourseRate, error) {
+	var courses []model.Course
+	courseQuery := database.DB.Select("id", "name")
+	switch role {
+	case "teacher":
+		courseQuery = courseQuery.Where("teacher_id = ?", userID)
+	case "assistant":
+		courseQuery = courseQuery.Where("id IN (?)", database.DB.Model(&model.CourseAssistant{}).Select("course_id").Where("assistant_id = ?", userID))
+	}
+	if err := courseQuery.Find(&courses).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]DashboardCourseRate, 0)
+	if len(courses) == 0 {
+		return result, nil
+	}
+	courseIDs := make([]uint, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.ID)
+	}
+
+	var sessionCounts []courseCount
+	if err := database.DB.Model(&model.CheckinSession{}).
+		Select("course_id, COUNT(*) AS count").
+		Where("course_id IN ? AND status = ? AND start_time >= ?", courseIDs, "ended", since).
+		Group("course_id").
+		Scan(&sessionCounts).Error; err != nil {
+		return nil, err
+	}
+	sessionMap := make(map[uint]int, len(sessionCounts))
+	for _, count := range sessionCounts {
+		sessionMap[count.CourseID] = count.Count
+	}
+
+	var enrolledCounts []courseCount
+	if err := database.DB.Model(&model.Enrollment{}).
+		Select("course_id, COUNT(*) AS count").
+		Where("course_id IN ?", courseIDs).
+		Group("course_id").
+		Scan(&enrolledCounts).Error; err != nil {
+		return nil, err
+	}
+	enrolledMap := make(map[uint]int, len(enrolledCounts))
+	for _, count := range enrolledCounts {
+		enrolledMap[count.CourseID] = count.Count
+	}
+
+	var statusCounts []courseStatusCount
+	if err := database.DB.Model(&model.CheckinRecord{}).
+		Select("checkin_records.course_id, checkin_records.status, COUNT(*) AS count").
+		Joins("JOIN checkin_sessions ON checkin_sessions.id = checkin_records.session_id").
+		Where("checkin_records.course_id IN ? AND checkin_sessions.status = ? AND checkin_sessions.start_time >= ?", courseIDs, "ended", since).
+		Group("checkin_records.course_id, checkin_records.status").
+		Scan(&statusCounts).Error; err != nil {
+		return nil, err
+	}
+	statusMap := make(map[uint]map[string]int)
+	for _, count := range statusCounts {
+		if statusMap[count.CourseID] == nil {
+			statusMap[count.CourseID] = make(map[string]int)
+		}
+		statusMap[count.CourseID][count.Status] = count.Count
+	}
+
+	for _, course := range courses {
+		sessions := sessionMap[course.ID]
+		enrolled := enrolledMap[course.ID]
+		if sessions == 0 || enrolled == 0 {
+			continue
+		}
+		counts := statusMap[course.ID]
+		result = append(result, DashboardCourseRate{
+			CourseID:       course.ID,
+			CourseName:     course.Name,
+			Sessions:       sessions,
+			Enrolled:       enrolled,
+			AttendanceRate: attendanceRate(counts["present"], counts["late"], counts["excused"], sessions*enrolled),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AttendanceRate < result[j].AttendanceRate
+	})
+	if len(result) > dashboardLowCourseLimit {
+		result = result[:dashboardLowCourseLimit]
+	}
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DetectionService 异常签到（疑似代签）检测服务
+type DetectionService struct{}
+
+const (
+	// sharedIPWindow 同一IP在该时间窗口内多名学生签到视为可疑
+	sharedIPWindow = 30 * time.Second
+	// sharedIPMinStudents 时间窗口内同一IP的学生数达到该值触发规则
+	sharedIPMinStudents = 3
+	// qrExposureWindow 二维码通常只在课堂开始时展示，超过该时长后的扫码签到可能是转发的签到码
+	qrExposureWindow = 2 * time.Minute
+	// flagScoreThreshold 可疑分数达到该值且命中设备规则时生成标记
+	flagScoreThreshold = 50
+	// deviceRule 设备级规则；校园网多人共用出口 IP，只凭 IP 和签到时间不足以判定代签
+	deviceRule = "shared_device"
+	// evaluationDelay 签到后延迟检测的时长，期间同一会话的签到只触发一次检测
+	evaluationDelay = 5 * time.Second
+)
+
+var (
+	pendingEvaluationsMu sync.Mutex
+	pendingEvaluations   = make(map[uint]bool) // 已排队等待检测的会话
+)
+
+// FlagReason 命中的检测规则及说明
+type FlagReason struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// scoreRecords 按规则为会话内的扫码签到记录打分，返回 记录ID -> 命中规则
+func scoreRecords(session model.CheckinSession, records []model.CheckinRecord) map[uint][]FlagReason {
+	reasons := make(map[uint][]FlagReason)
+
+	// 规则一：同一设备（IP + User-Agent）为多名学生签到
+	// 同一教室的网络出口和常见浏览器标识可能相同，单独命中不足以生成标记，需配合其他规则
+	devices := make(map[string][]model.CheckinRecord)
+	for _, record := range records {
+		if record.UserAgent != "" {
+			key := record.ClientIP + "|" + record.UserAgent
+			devices[key] = append(devices[key], record)
+		}
+	}
+	for _, group := range devices {
+		if len(group) < 2 {
+			continue
+		}
+		for _, record := range group {
+			reasons[record.ID] = append(reasons[record.ID], FlagReason{
+				Rule:   deviceRule,
+				Score:  30,
+				Detail: fmt.Sprintf("同一设备（IP %s）共为 %d 名学生签到", record.ClientIP, len(group)),
+			})
+		}
+	}
+
+	// 规则二：短时间内同一IP有多名学生签到
+	for i, record := range records {
+		if record.ClientIP == "" {
+			continue
+		}
+		count := 0
+		for j, other := range records {
+			if i == j || other.ClientIP != record.ClientIP {
+				continue
+			}
+			diff := other.CheckinTime.Sub(record.CheckinTime)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff <= sharedIPWindow {
+				count++
+			}
+		}
+		if count+1 >= sharedIPMinStudents {
+			reasons[record.ID] = append(reasons[record.ID], FlagReason{
+				Rule:   "shared_ip_burst",
+				Score:  40,
+				Detail: fmt.Sprintf("%d 秒内同一IP %s 有 %d 名学生签到", int(sharedIPWindow.Seconds()), record.ClientIP, count+1),
+			})
+		}
+	}
+
+	// 规则三：二维码展示时段结束后才扫码签到
+	for _, record := range records {
+		elapsed := record.CheckinTime.Sub(session.StartTime)
+		if elapsed > qrExposureWindow {
+			reasons[record.ID] = append(reasons[record.ID], FlagReason{
+				Rule:   "after_qr_exposure",
+				Score:  20,
+				Detail: fmt.Sprintf("签到发生在开始后 %d 秒，超过二维码展示时长 %d 秒", int(elapsed.Seconds()), int(qrExposureWindow.Seconds())),
+			})
+		}
+	}
+
+	return reasons
+}
+
+// ScheduleEvaluation 延迟检测会话，会话已在排队时不重复调度
+// 签到高峰时每次签到都扫描整个会话代价过高，完整检测仍由会话结束后的定时任务兜底
+func (ds *DetectionService) ScheduleEvaluation(sessionID uint) {
+	pendingEvaluationsMu.Lock()
+	defer pendingEvaluationsMu.Unlock()
+	if pendingEvaluations[sessionID] {
+		return
+	}
+	pendingEvaluations[sessionID] = true
+
+	time.AfterFunc(evaluationDelay, func() {
+		// 先出队再检测，检测期间的新签到会重新调度
+		pendingEvaluationsMu.Lock()
+		delete(pendingEvaluations, sessionID)
+		pendingEvaluationsMu.Unlock()
+
+		if err := ds.EvaluateSession(sessionID); err != nil {
+			log.Printf("会话 %d 异常签到检测失败: %v", sessionID, err)
+		}
+	})
+}
+
+// hasRule 判断是否命中指定规则
+func hasRule(reasons []FlagReason, rule string) bool {
+	for _, reason := range reasons {
+		if reason.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluateSession 检测会话内的扫码签到记录，生成或更新待复核的标记
+// 只有命中设备规则且分数达到阈值的记录才会被标记；已复核的标记不会被覆盖，不再满足条件的待复核标记会被移除
+func (ds *DetectionService) EvaluateSession(sessionID uint) error {
+	var session model.CheckinSession
+	if err := database.DB.First(&session, sessionID).Error; err != nil {
+		return err
+	}
+
+	// 只检测带有客户端信息的扫码签到，教师补签的记录不参与
+	var records []model.CheckinRecord
+	if err := database.DB.Where("session_id = ? AND client_ip <> ''", sessionID).Find(&records).Error; err != nil {
+		return err
+	}
+
+	scored := scoreRecords(session, records)
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		flaggedIDs := make([]uint, 0)
+		for _, record := range records {
+			reasons := scored[record.ID]
+			score := 0
+			for _, reason := range reasons {
+				score += reason.Score
+			}
+			if score < flagScoreThreshold || !hasRule(reasons, deviceRule) {
+				continue
+			}
+			flaggedIDs = append(flaggedIDs, record.ID)
+
+			reasonsJSON, err := json.Marshal(reasons)
+			if err != nil {
+				return err
+			}
+			flag := model.CheckinFlag{
+				RecordID:  record.ID,
+				SessionID: session.ID,
+				CourseID:  session.CourseID,
+				StudentID: record.StudentID,
+				Score:     score,
+				Reasons:   string(reasonsJSON),
+				Status:    "pending",
+			}
+			// 已存在的标记只更新分数和原因，复核状态保持不变
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "record_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"score", "reasons"}),
+			}).Create(&flag).Error; err != nil {
+				return err
+			}
+		}
+
+		query := tx.Unscoped().Where("session_id = ? AND status = ?", session.ID, "pending")
+		if len(flaggedIDs) > 0 {
+			query = query.Where("record_id NOT IN ?", flaggedIDs)
+		}
+		return query.Delete(&model.CheckinFlag{}).Error
+	})
+}
+
+// GetFlags 按角色范围查询异常签到标记：教师为其课程，助教为被分配课程，管理员不限制
+func (ds *DetectionService) GetFlags(userID uint, role string, status string, sessionID uint) ([]model.CheckinFlag, error) {
+	var flags []model.CheckinFlag
+	query := database.DB.Preload("Record").Preload("Student")
+
+	switch role {
+	case "admin":
+	case "teacher":
+		query = query.Where("course_id IN (?)", database.DB.Model(&model.Course{}).Select("id").Where("teacher_id = ?", userID))
+	case "assistant":
+		query = query.Where("course_id IN (?)", database.DB.Model(&model.CourseAssistant{}).Select("course_id").Where("assistant_id = ?", userID))
+	default:
+		return flags, nil
+	}
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if sessionID != 0 {
+		query = query.Where("session_id = ?", sessionID)
+	}
+	err := query.Order("score DESC, id").Find(&flags).Error
+	return flags, err
+}
+
+// GetFlagByID 根据ID获取异常签到标记
+func (ds *DetectionService) GetFlagByID(id uint) (*model.CheckinFlag, error) {
+	var flag model.CheckinFlag
+	if err := database.DB.First(&flag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("标记不存在")
+		}
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// ReviewFlag 复核标记：confirm 确认代签并将签到记录改为缺勤，clear 解除标记
+func (ds *DetectionService) ReviewFlag(id, reviewerID uint, action, comment string) (*model.CheckinFlag, error) {
+	flag, err := ds.GetFlagByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if flag.Status != "pending" {
+		return nil, errors.New("该标记已复核")
+	}
+
+	switch action {
+	case "confirm":
+		flag.Status = "confirmed"
+	case "clear":
+		flag.Status = "cleared"
+	default:
+		return nil, errors.New("无效的复核操作")
+	}
+
+	now := time.Now()
+	flag.ReviewerID = &reviewerID
+	flag.ReviewComment = comment
+	flag.ReviewedAt = &now
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 只更新仍待复核的标记，避免多人同时复核时互相覆盖
+		result := tx.Model(flag).Where("status = ?", "pending").
+			Select("status", "reviewer_id", "review_comment", "reviewed_at").Updates(flag)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("该标记已复核")
+		}
+		if flag.Status == "confirmed" {
+			return tx.Model(&model.CheckinRecord{}).Where("id = ?", flag.RecordID).Update("status", "absent").Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flag, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"backend/config"
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/mailer"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/weekly_digest.html templates/weekly_digest.txt
+var digestTemplates embed.FS
+
+// digestRiskRate 课程出勤率低于该值的学生列入周报的关注名单
+const digestRiskRate = 0.8
+
+var digestFuncs = map[string]interface{}{
+	"percent": func(rate float64) string { return fmt.Sprintf("%.1f%%", rate*100) },
+}
+
+var (
+	digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("weekly_digest.html").Funcs(digestFuncs).ParseFS(digestTemplates, "templates/weekly_digest.html"))
+	digestTextTemplate = texttemplate.Must(texttemplate.New("weekly_digest.txt").Funcs(digestFuncs).ParseFS(digestTemplates, "templates/weekly_digest.txt"))
+)
+
+// DigestService 教师考勤周报服务
+type DigestService struct {
+	mailer        mailer.Mailer
+	mailerOnce    sync.Once
+	reportService ReportService
+}
+
+// getMailer 未指定发送器时使用配置中的 SMTP 服务
+// 服务实例在定时任务和请求处理的多个 goroutine 间共享，只初始化一次
+func (ds *DigestService) getMailer() mailer.Mailer {
+	ds.mailerOnce.Do(func() {
+		if ds.mailer == nil {
+			ds.mailer = mailer.NewSMTPMailer(config.Cfg)
+		}
+	})
+	return ds.mailer
+}
+
+// CourseDigest 周报中单门课程的考勤情况
+type CourseDigest struct {
+	CourseCode     string
+	CourseName     string
+	Sessions       []SessionAttendancePoint
+	AttendanceRate float64
+	AtRisk         []StudentAttendanceSummary
+}
+
+// TeacherDigest 发送给一位教师的周报
+type TeacherDigest struct {
+	TeacherName string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Courses     []CourseDigest
+}
+
+// BuildTeacherDigest 汇总教师所有课程在 [start, end) 内的考勤情况
+func (ds *DigestService) BuildTeacherDigest(teacher model.User, start, end time.Time) (*TeacherDigest, error) {
+	var courses []model.Course
+	if err := database.DB.Where("teacher_id = ?", teacher.ID).Order("course_code").Find(&courses).Error; err != nil {
+		return nil, err
+	}
+
+	digest := &TeacherDigest{
+		TeacherName: teacher.Name,
+		PeriodStart: start,
+		PeriodEnd:   end.AddDate(0, 0, -1),
+		Courses:     make([]CourseDigest, 0, len(courses)),
+	}
+	for _, course := range courses {
+		points, err := ds.reportService.courseAttendanceTrend(course.ID, DateRange{Start: start, End: end})
+		if err != nil {
+			return nil, err
+		}
+
+		courseDigest := CourseDigest{
+			CourseCode: course.CourseCode,
+			CourseName: course.Name,
+			Sessions:   points,
+		}
+		var present, late, excused, expected int
+		for _, point := range points {
+			present += point.Present
+			late += point.Late
+			excused += point.Excused
+			expected += point.Enrolled
+		}
+		courseDigest.AttendanceRate = attendanceRate(present, late, excused, expected)
+
+		summaries, err := ds.reportService.GetCourseStudentReport(course.ID, DateRange{})
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range summaries {
+			if summary.TotalSessions > summary.Excused && summary.AttendanceRate < digestRiskRate {
+				courseDigest.AtRisk = append(courseDigest.AtRisk, summary)
+			}
+		}
+
+		digest.Courses = append(digest.Courses, courseDigest)
+	}
+	return digest, nil
+}
+
+// SendWeeklyDigests 向所有配置了邮箱且有课程的教师发送截至 now 的一周考勤周报，返回成功发送的数量
+func (ds *DigestService) SendWeeklyDigests(now time.Time) (int, error) {
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := end.AddDate(0, 0, -7)
+
+	var teachers []model.User
+	if err := database.DB.Where("role = ? AND email IS NOT NULL AND email <> ''", "teacher").
+		Where("id IN (?)", database.DB.Model(&model.Course{}).Select("teacher_id")).
+		Find(&teachers).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, teacher := range teachers {
+		if err := ds.SendTeacherDigest(teacher, start, end); err != nil {
+			log.Printf("发送教师 %d 考勤周报失败: %v", teacher.ID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// SendTeacherDigest 生成并发送一位教师的周报，并记录发送结果
+func (ds *DigestService) SendTeacherDigest(teacher model.User, start, end time.Time) error {
+	digest, err := ds.BuildTeacherDigest(teacher, start, end)
+	if err != nil {
+		return err
+	}
+
+	var html, text bytes.Buffer
+	if err := digestHTMLTemplate.Execute(&html, digest); err != nil {
+		return err
+	}
+	if err := digestTextTemplate.Execute(&text, digest); err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("考勤周报（%s 至 %s）", digest.PeriodStart.Format("01-02"), digest.PeriodEnd.Format("01-02"))
+	return sendLoggedEmail(ds.getMailer(), "weekly_digest", teacher, mailer.Message{
+		To:      []string{teacher.Email},
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
+
+// WeeklyDigestDue 判断当前是否到了周报发送时间且本周尚未发送过，未配置邮件服务时不发送
+func (ds *DigestService) WeeklyDigestDue(now time.Time) bool {
+	if smtp, ok := ds.getMailer().(*mailer.SMTPMailer); ok && !smtp.Enabled() {
+		return false
+	}
+	if int(now.Weekday()) != config.Cfg.DigestWeekday || now.Hour() != config.Cfg.DigestHour {
+		return false
+	}
+
+	var count int64
+	database.DB.Model(&model.EmailLog{}).
+		Where("kind = ? AND sent_at >= ?", "weekly_digest", now.Add(-24*time.Hour)).
+		Count(&count)
+	return count == 0
+}
+
+// GetEmailLogs 获取最近的邮件发送记录
+func (ds *DigestService) GetEmailLogs(kind string, limit int) ([]model.EmailLog, error) {
+	var logs []model.EmailLog
+	query := database.DB.Order("sent_at DESC").Limit(limit)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/mailer"
+	"log"
+	"time"
+)
+
+// sendLoggedEmail 向用户发送邮件并写入发送记录，返回发送结果
+func sendLoggedEmail(m mailer.Mailer, kind string, user model.User, msg mailer.Message) error {
+	sendErr := m.Send(msg)
+
+	emailLog := model.EmailLog{
+		Kind:      kind,
+		UserID:    user.ID,
+		Recipient: user.Email,
+		Subject:   msg.Subject,
+		Status:    "sent",
+		SentAt:    time.Now(),
+	}
+	if sendErr != nil {
+		emailLog.Status = "failed"
+		emailLog.Error = sendErr.Error()
+	}
+	if err := database.DB.Create(&emailLog).Error; err != nil {
+		log.Printf("记录邮件发送日志失败: %v", err)
+	}
+	return sendErr
+}
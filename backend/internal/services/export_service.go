@@ -0,0 +1,194 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// statusLabels 签到状态的中文名称
+var statusLabels = map[string]string{
+	"present": "出勤",
+	"late":    "迟到",
+	"absent":  "缺勤",
+	"excused": "请假",
+}
+
+// StatusLabel 获取签到状态的中文名称，未知状态原样返回
+func StatusLabel(status string) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+// SanitizeCell 防止公式注入：以 = + - @ 或制表符、回车开头的文本前加单引号，
+// 使表格软件将其视为纯文本而不是公式
+func SanitizeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+const (
+	summarySheet = "汇总"
+	detailSheet  = "考勤明细"
+)
+
+// WriteAttendanceMatrixXLSX 将课程考勤矩阵写为 xlsx 工作簿
+// 明细表使用流式写入，避免大课程占用过多内存；缺勤和迟到单元格分别标红、标黄
+func WriteAttendanceMatrixXLSX(w io.Writer, matrix *AttendanceMatrix, dateRange DateRange) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", summarySheet); err != nil {
+		return err
+	}
+	if _, err := f.NewSheet(detailSheet); err != nil {
+		return err
+	}
+
+	if err := writeSummarySheet(f, matrix, dateRange); err != nil {
+		return err
+	}
+	if err := writeDetailSheet(f, matrix); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
+
+// writeSummarySheet 写入汇总表：课程信息与整体考勤统计
+func writeSummarySheet(f *excelize.File, matrix *AttendanceMatrix, dateRange DateRange) error {
+	var present, late, absent, excused int
+	for _, row := range matrix.Rows {
+		present += row.Present
+		late += row.Late
+		absent += row.Absent
+		excused += row.Excused
+	}
+	total := len(matrix.Rows) * len(matrix.Sessions)
+
+	rangeText := "全部"
+	if !dateRange.Start.IsZero() || !dateRange.End.IsZero() {
+		start, end := "", ""
+		if !dateRange.Start.IsZero() {
+			start = dateRange.Start.Format("2006-01-02")
+		}
+		if !dateRange.End.IsZero() {
+			end = dateRange.End.AddDate(0, 0, -1).Format("2006-01-02")
+		}
+		rangeText = start + " ~ " + end
+	}
+
+	rows := [][]interface{}{
+		{"课程编号", SanitizeCell(matrix.Course.CourseCode)},
+		{"课程名称", SanitizeCell(matrix.Course.Name)},
+		{"任课教师", SanitizeCell(matrix.Course.Teacher.Name)},
+		{"学期", SanitizeCell(matrix.Course.Semester)},
+		{"统计范围", rangeText},
+		{"签到次数", len(matrix.Sessions)},
+		{"选课人数", len(matrix.Rows)},
+		{"出勤人次", present},
+		{"迟到人次", late},
+		{"缺勤人次", absent},
+		{"请假人次", excused},
+		{"整体出勤率", attendanceRate(present, late, excused, total)},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(summarySheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	percentStyle, err := f.NewStyle(&excelize.Style{NumFmt: 10})
+	if err != nil {
+		return err
+	}
+	rateCell, _ := excelize.CoordinatesToCellName(2, len(rows))
+	if err := f.SetCellStyle(summarySheet, rateCell, rateCell, percentStyle); err != nil {
+		return err
+	}
+	return f.SetColWidth(summarySheet, "A", "B", 20)
+}
+
+// writeDetailSheet 流式写入明细表：学生为行，每次签到为一列，末尾为合计
+func writeDetailSheet(f *excelize.File, matrix *AttendanceMatrix) error {
+	absentStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+		Font: &excelize.Font{Color: "9C0006"},
+	})
+	if err != nil {
+		return err
+	}
+	lateStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFEB9C"}},
+		Font: &excelize.Font{Color: "9C5700"},
+	})
+	if err != nil {
+		return err
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	percentStyle, err := f.NewStyle(&excelize.Style{NumFmt: 10})
+	if err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(detailSheet)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetColWidth(1, 2, 14); err != nil {
+		return err
+	}
+
+	header := []interface{}{
+		excelize.Cell{StyleID: headerStyle, Value: "学号"},
+		excelize.Cell{StyleID: headerStyle, Value: "姓名"},
+	}
+	for _, session := range matrix.Sessions {
+		header = append(header, excelize.Cell{StyleID: headerStyle, Value: session.StartTime.Format("01-02 15:04")})
+	}
+	for _, title := range []string{"出勤", "迟到", "缺勤", "请假", "出勤率"} {
+		header = append(header, excelize.Cell{StyleID: headerStyle, Value: title})
+	}
+	if err := sw.SetRow("A1", header, excelize.RowOpts{}); err != nil {
+		return err
+	}
+
+	for i, row := range matrix.Rows {
+		values := make([]interface{}, 0, len(header))
+		values = append(values, SanitizeCell(row.Username), SanitizeCell(row.StudentName))
+		for _, status := range row.Statuses {
+			cell := excelize.Cell{Value: StatusLabel(status)}
+			switch status {
+			case "absent":
+				cell.StyleID = absentStyle
+			case "late":
+				cell.StyleID = lateStyle
+			}
+			values = append(values, cell)
+		}
+		values = append(values,
+			row.Present,
+			row.Late,
+			row.Absent,
+			row.Excused,
+			excelize.Cell{StyleID: percentStyle, Value: row.AttendanceRate},
+		)
+
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := sw.SetRow(cell, values); err != nil {
+			return fmt.Errorf("写入第 %d 行失败: %w", i+2, err)
+		}
+	}
+
+	return sw.Flush()
+}
@@ -0,0 +1,26 @@
+package services
+
+import "testing"
+
+func TestSanitizeCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"张三", "张三"},
+		{"2021001", "2021001"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1", "'+1"},
+		{"-1+2", "'-1+2"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeCell(tt.value); got != tt.want {
+			t.Errorf("SanitizeCell(%q) = %q，期望 %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"backend/config"
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"gorm.io/gorm"
+)
+
+// ldapTimeout 连接和查询目录的超时时间
+const ldapTimeout = 10 * time.Second
+
+// LDAPAuthProvider 使用统一身份认证目录校验密码，首次登录时自动开通账号
+type LDAPAuthProvider struct {
+	cfg         *config.Config
+	roleMap     map[string]string
+	defaultRole string
+}
+
+// NewLDAPAuthProvider 根据配置创建 LDAP 认证方式
+func NewLDAPAuthProvider(cfg *config.Config) *LDAPAuthProvider {
+	p := &LDAPAuthProvider{cfg: cfg, roleMap: parseRoleMap(cfg.LDAPRoleMap)}
+	if cfg.LDAPDefaultRole != "" {
+		if isExternalRole(cfg.LDAPDefaultRole) {
+			p.defaultRole = cfg.LDAPDefaultRole
+		} else {
+			log.Printf("LDAP 默认角色 %s 无效，已忽略", cfg.LDAPDefaultRole)
+		}
+	}
+	return p
+}
+
+// Name 认证方式名称
+func (p *LDAPAuthProvider) Name() string {
+	return AuthSourceLDAP
+}
+
+// Authenticate 以服务账号查找用户条目，再用用户的 DN 和密码绑定校验
+func (p *LDAPAuthProvider) Authenticate(username, password string) (*model.User, error) {
+	if p.cfg.LDAPURL == "" {
+		return nil, ErrUnknownAccount
+	}
+	// 空密码会被很多目录当作匿名绑定并返回成功
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	// 不可关联的本地账号不查询目录，目录不可用时也不影响本地账号登录
+	var existing model.User
+	err := database.DB.Where("username = ?", username).First(&existing).Error
+	if err == nil && existing.AuthSource != AuthSourceLDAP {
+		if existing.AuthSource != AuthSourceLocal || !p.cfg.LDAPLinkLocal || existing.Role == "admin" {
+			return nil, ErrUnknownAccount
+		}
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	conn, err := p.dial()
+	if err != nil {
+		return nil, fmt.Errorf("连接 LDAP 失败: %w", err)
+	}
+	defer conn.Close()
+
+	if p.cfg.LDAPBindDN != "" {
+		if err := conn.Bind(p.cfg.LDAPBindDN, p.cfg.LDAPBindPassword); err != nil {
+			return nil, fmt.Errorf("LDAP 服务账号绑定失败: %w", err)
+		}
+	}
+
+	entry, err := p.findUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP 用户绑定失败: %w", err)
+	}
+
+	profile := p.profile(entry, username)
+	if profile.Role == "" {
+		log.Printf("LDAP 用户 %s 无法映射到系统角色，拒绝登录", profile.Username)
+		return nil, ErrInvalidCredentials
+	}
+	return provisionExternalUser(AuthSourceLDAP, profile, p.cfg.LDAPLinkLocal)
+}
+
+// dial 连接目录，ldap:// 地址按配置升级为 StartTLS
+func (p *LDAPAuthProvider) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(p.cfg.LDAPURL, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if p.cfg.LDAPStartTLS {
+		u, err := url.Parse(p.cfg.LDAPURL)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: u.Hostname()}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// findUser 按登录名查找唯一的用户条目，找不到时交给其他认证方式
+func (p *LDAPAuthProvider) findUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(
+		p.cfg.LDAPBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		fmt.Sprintf(p.cfg.LDAPUserFilter, ldap.EscapeFilter(username)),
+		[]string{p.cfg.LDAPAttrUsername, p.cfg.LDAPAttrName, p.cfg.LDAPAttrEmail, p.cfg.LDAPAttrRole},
+		nil,
+	)
+
+	result, err := conn.Search(request)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("LDAP 查询失败: %w", err)
+	}
+	switch {
+	case result == nil || len(result.Entries) == 0:
+		return nil, ErrUnknownAccount
+	case len(result.Entries) > 1:
+		log.Printf("LDAP 中登录名 %s 对应多个条目，拒绝登录", username)
+		return nil, ErrInvalidCredentials
+	}
+	return result.Entries[0], nil
+}
+
+// profile 按配置的属性名读取用户信息
+func (p *LDAPAuthProvider) profile(entry *ldap.Entry, username string) *ExternalProfile {
+	profile := &ExternalProfile{
+		Username: entry.GetAttributeValue(p.cfg.LDAPAttrUsername),
+		Name:     entry.GetAttributeValue(p.cfg.LDAPAttrName),
+		Email:    entry.GetAttributeValue(p.cfg.LDAPAttrEmail),
+		Role:     p.mapRole(entry.GetAttributeValues(p.cfg.LDAPAttrRole)),
+	}
+	if profile.Username == "" {
+		profile.Username = username
+	}
+	return profile
+}
+
+// mapRole 将目录中的角色属性映射为系统角色，多个值时取优先级最高的角色
+func (p *LDAPAuthProvider) mapRole(values []string) string {
+	if role := mapExternalRole(p.roleMap, values); role != "" {
+		return role
+	}
+	return p.defaultRole
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 列表分页参数
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListSpec 描述列表接口允许的排序字段和筛选条件，键均为查询参数名
+type ListSpec struct {
+	IDColumn       string            // 主键列，用于游标分页和默认排序
+	Sorts          map[string]string // 可排序字段，值为数据库列名
+	Filters        map[string]string // 筛选条件，值为带一个 ? 占位符的 SQL 条件，如 "role = ?"
+	KeywordColumns []string          // keyword 模糊匹配的列，任意一列匹配即可
+}
+
+// ListQuery 列表接口的分页、排序和筛选参数
+// 携带 page、page_size 或 cursor 时分页返回，否则返回全部结果，兼容未分页的旧客户端
+type ListQuery struct {
+	Page     int
+	PageSize int
+	Cursor   uint              // 游标分页：上一页最后一条记录的 ID，cursor=0 表示从头开始
+	Sort     string            // 排序字段，前缀 - 表示降序
+	Keyword  string            // 名称等字段的模糊搜索关键字
+	Filters  map[string]string // 筛选参数，键为查询参数名
+
+	spec      ListSpec
+	paginated bool
+	useCursor bool
+}
+
+// PageResult 分页列表的响应
+type PageResult struct {
+	Items      interface{} `json:"items"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page,omitempty"`
+	PageSize   int         `json:"page_size"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// ParseListQuery 从查询参数解析分页、排序和筛选条件，不在 spec 中的排序字段视为错误
+func ParseListQuery(values url.Values, spec ListSpec) (*ListQuery, error) {
+	q := &ListQuery{
+		Sort:    strings.TrimSpace(values.Get("sort")),
+		Keyword: strings.TrimSpace(values.Get("keyword")),
+		Filters: make(map[string]string),
+		spec:    spec,
+	}
+
+	if v := values.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return nil, errors.New("page 必须为正整数")
+		}
+		q.Page, q.paginated = page, true
+	}
+	if v := values.Get("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 {
+			return nil, errors.New("page_size 必须为正整数")
+		}
+		q.PageSize, q.paginated = size, true
+	}
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, errors.New("cursor 无效")
+		}
+		q.Cursor, q.paginated, q.useCursor = uint(cursor), true, true
+	}
+	if q.useCursor && q.Page > 0 {
+		return nil, errors.New("page 和 cursor 不能同时使用")
+	}
+
+	if q.paginated {
+		if q.PageSize == 0 {
+			q.PageSize = DefaultPageSize
+		}
+		if q.PageSize > MaxPageSize {
+			q.PageSize = MaxPageSize
+		}
+		// 只给出 page_size 时从第一页开始
+		if q.Page == 0 && !q.useCursor {
+			q.Page = 1
+		}
+	}
+
+	if q.Sort != "" {
+		field := strings.TrimPrefix(q.Sort, "-")
+		if _, ok := spec.Sorts[field]; !ok {
+			return nil, fmt.Errorf("不支持的排序字段: %s", field)
+		}
+		// 游标按主键递增或递减翻页，只能配合主键排序
+		if q.useCursor && spec.Sorts[field] != spec.IDColumn {
+			return nil, errors.New("游标分页只支持按 id 排序")
+		}
+	}
+
+	for key := range spec.Filters {
+		if v := strings.TrimSpace(values.Get(key)); v != "" {
+			q.Filters[key] = v
+		}
+	}
+	return q, nil
+}
+
+// Paginated 是否请求了分页
+func (q *ListQuery) Paginated() bool {
+	return q.paginated
+}
+
+// Apply 应用筛选条件并统计总数，返回排序、分页后的查询
+// 未分页时不单独统计，total 为 -1，由调用方按结果条数返回
+func (q *ListQuery) Apply(query *gorm.DB) (*gorm.DB, int64, error) {
+	for key, value := range q.Filters {
+		if condition, ok := q.spec.Filters[key]; ok {
+			query = query.Where(condition, value)
+		}
+	}
+	if q.Keyword != "" && len(q.spec.KeywordColumns) > 0 {
+		pattern := "%" + escapeLike(q.Keyword) + "%"
+		conditions := make([]string, len(q.spec.KeywordColumns))
+		args := make([]interface{}, len(q.spec.KeywordColumns))
+		for i, column := range q.spec.KeywordColumns {
+			conditions[i] = column + " LIKE ? ESCAPE '!'"
+			args[i] = pattern
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+
+	total := int64(-1)
+	if q.paginated {
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	desc := strings.HasPrefix(q.Sort, "-")
+	column := q.spec.IDColumn
+	if q.Sort != "" {
+		column = q.spec.Sorts[strings.TrimPrefix(q.Sort, "-")]
+	}
+	if q.useCursor && q.Cursor > 0 {
+		if desc {
+			query = query.Where(q.spec.IDColumn+" < ?", q.Cursor)
+		} else {
+			query = query.Where(q.spec.IDColumn+" > ?", q.Cursor)
+		}
+	}
+	order := column
+	if desc {
+		order += " DESC"
+	}
+	query = query.Order(order)
+	// 排序字段可能重复，追加主键保证翻页顺序稳定
+	if column != q.spec.IDColumn {
+		query = query.Order(q.spec.IDColumn)
+	}
+
+	if q.paginated {
+		query = query.Limit(q.PageSize)
+		if !q.useCursor {
+			query = query.Offset((q.Page - 1) * q.PageSize)
+		}
+	}
+	return query, total, nil
+}
+
+// Result 组装分页响应，n 为本页记录数，lastID 为本页最后一条记录的 ID
+func (q *ListQuery) Result(items interface{}, total int64, n int, lastID uint) PageResult {
+	result := PageResult{Items: items, Total: total, PageSize: q.PageSize}
+	if q.useCursor {
+		// 本页已满时才可能还有下一页
+		if n == q.PageSize {
+			result.NextCursor = strconv.FormatUint(uint64(lastID), 10)
+		}
+	} else {
+		result.Page = q.Page
+	}
+	return result
+}
+
+// escapeLike 转义 LIKE 模式中的通配符，配合 ESCAPE '!' 使用
+// 不用反斜杠作转义符，它在 MySQL 字符串中本身需要转义，各数据库的默认行为也不一致
+func escapeLike(s string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(s)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"backend/pkg/database"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// listItem 列表查询测试用的简单模型
+type listItem struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+var listItemSpec = ListSpec{
+	IDColumn:       "id",
+	Sorts:          map[string]string{"id": "id", "name": "name"},
+	Filters:        map[string]string{"name": "name = ?"},
+	KeywordColumns: []string{"name"},
+}
+
+// queryListItems 按查询参数查询 listItem，返回本页 ID 和分页结果
+func queryListItems(t *testing.T, rawQuery string) ([]uint, PageResult) {
+	t.Helper()
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatalf("解析查询参数失败: %v", err)
+	}
+	q, err := ParseListQuery(values, listItemSpec)
+	if err != nil {
+		t.Fatalf("ParseListQuery(%q) 失败: %v", rawQuery, err)
+	}
+	query, total, err := q.Apply(database.DB.Model(&listItem{}))
+	if err != nil {
+		t.Fatalf("Apply 失败: %v", err)
+	}
+	var items []listItem
+	if err := query.Find(&items).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	var lastID uint
+	if len(items) > 0 {
+		lastID = items[len(items)-1].ID
+	}
+	return ids, q.Result(items, total, len(items), lastID)
+}
+
+func TestParseListQueryPageSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		paginated bool
+		page      int
+		pageSize  int
+	}{
+		{"未分页", "", false, 0, 0},
+		{"只给出页码时使用默认每页条数", "page=2", true, 2, DefaultPageSize},
+		{"只给出每页条数时从第一页开始", "page_size=5", true, 1, 5},
+		{"每页条数超过上限时截断", "page=1&page_size=1000", true, 1, MaxPageSize},
+		{"游标分页不设置页码", "cursor=10&page_size=5", true, 0, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			q, err := ParseListQuery(values, listItemSpec)
+			if err != nil {
+				t.Fatalf("ParseListQuery(%q) 失败: %v", tt.query, err)
+			}
+			if q.Paginated() != tt.paginated || q.Page != tt.page || q.PageSize != tt.pageSize {
+				t.Errorf("ParseListQuery(%q) = paginated %v, page %d, page_size %d，期望 %v, %d, %d",
+					tt.query, q.Paginated(), q.Page, q.PageSize, tt.paginated, tt.page, tt.pageSize)
+			}
+		})
+	}
+}
+
+func TestParseListQueryRejectsInvalidParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"页码和游标同时使用", "page=2&cursor=10"},
+		{"未知排序字段", "sort=password_hash"},
+		{"未知降序排序字段", "sort=-password_hash"},
+		{"游标分页按非主键排序", "cursor=10&sort=name"},
+		{"页码不是正整数", "page=0"},
+		{"每页条数不是正整数", "page_size=-1"},
+		{"游标不是数字", "cursor=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			if _, err := ParseListQuery(values, listItemSpec); err == nil {
+				t.Errorf("ParseListQuery(%q) 应返回错误", tt.query)
+			}
+		})
+	}
+}
+
+func TestListQueryCursorDescending(t *testing.T) {
+	setupTestDB(t, &listItem{})
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		database.DB.Create(&listItem{Name: name})
+	}
+
+	pages := []struct {
+		query      string
+		ids        []uint
+		nextCursor string
+	}{
+		{"sort=-id&page_size=2&cursor=0", []uint{5, 4}, "4"},
+		{"sort=-id&page_size=2&cursor=4", []uint{3, 2}, "2"},
+		{"sort=-id&page_size=2&cursor=2", []uint{1}, ""},
+	}
+	for _, page := range pages {
+		ids, result := queryListItems(t, page.query)
+		if !reflect.DeepEqual(ids, page.ids) {
+			t.Errorf("%s: 返回 %v，期望 %v", page.query, ids, page.ids)
+		}
+		if result.NextCursor != page.nextCursor {
+			t.Errorf("%s: next_cursor 为 %q，期望 %q", page.query, result.NextCursor, page.nextCursor)
+		}
+		if result.Total != 5 {
+			t.Errorf("%s: total 为 %d，期望 5", page.query, result.Total)
+		}
+	}
+}
+
+func TestListQueryKeywordEscapesWildcards(t *testing.T) {
+	setupTestDB(t, &listItem{})
+	for _, name := range []string{"50%off", "500", "a_b", "axb", "hi!", "hi"} {
+		database.DB.Create(&listItem{Name: name})
+	}
+
+	tests := []struct {
+		keyword string
+		ids     []uint
+	}{
+		{"%", []uint{1}},
+		{"_", []uint{3}},
+		{"!", []uint{5}},
+		{"50", []uint{1, 2}},
+	}
+	for _, tt := range tests {
+		ids, _ := queryListItems(t, url.Values{"keyword": {tt.keyword}, "page": {"1"}}.Encode())
+		if !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("keyword %q: 返回 %v，期望 %v", tt.keyword, ids, tt.ids)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"backend/config"
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/mailer"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 登录限制参数
+const (
+	userMaxFailures   = 5                // 同一账号连续失败次数上限
+	failureWindow     = 15 * time.Minute // 超过该时间没有失败则重新计数
+	baseLockout       = 5 * time.Minute  // 账号首次锁定时长，之后每次翻倍
+	maxLockout        = 24 * time.Hour   // 最长锁定时长
+	lockoutCountReset = 24 * time.Hour   // 超过该时间没有失败则锁定次数清零
+
+	// 同一 IP 按固定时间窗口限流，校园网 NAT 后有大量用户共用出口 IP，阈值需足够高且不逐次加长
+	ipMaxFailures = 200
+	ipWindow      = 10 * time.Minute
+
+	// 找回密码请求同样按固定时间窗口限流，防止批量探测账号和邮件轰炸
+	resetUserMaxRequests = 3
+	resetIPMaxRequests   = 20
+	resetWindow          = 15 * time.Minute
+)
+
+// 登录限制类型
+const (
+	ThrottleKindUser      = "user"
+	ThrottleKindIP        = "ip"
+	ThrottleKindResetUser = "reset_user"
+	ThrottleKindResetIP   = "reset_ip"
+)
+
+// LockedError 账号或 IP 处于锁定状态
+type LockedError struct {
+	Kind        string
+	LockedUntil time.Time
+}
+
+func (e *LockedError) Error() string {
+	switch e.Kind {
+	case ThrottleKindIP:
+		return fmt.Sprintf("当前网络登录失败次数过多，请于 %s 后再试", e.LockedUntil.Format("2006-01-02 15:04:05"))
+	case ThrottleKindResetUser, ThrottleKindResetIP:
+		return fmt.Sprintf("找回密码请求过于频繁，请于 %s 后再试", e.LockedUntil.Format("2006-01-02 15:04:05"))
+	}
+	return fmt.Sprintf("账号登录失败次数过多，已被锁定至 %s", e.LockedUntil.Format("2006-01-02 15:04:05"))
+}
+
+// LoginThrottleService 登录失败限制：账号连续失败超过上限后锁定，锁定时长逐次翻倍；
+// IP 按固定时间窗口统计失败次数，超过上限后在窗口结束前拒绝登录
+// 账号记录以小写用户名为键，与用户名不区分大小写的规则一致
+type LoginThrottleService struct {
+	mailer     mailer.Mailer
+	mailerOnce sync.Once
+}
+
+// getMailer 未指定发送器时使用配置中的 SMTP 服务
+// 服务实例在定时任务和请求处理的多个 goroutine 间共享，只初始化一次
+func (ls *LoginThrottleService) getMailer() mailer.Mailer {
+	ls.mailerOnce.Do(func() {
+		if ls.mailer == nil {
+			ls.mailer = mailer.NewSMTPMailer(config.Cfg)
+		}
+	})
+	return ls.mailer
+}
+
+// CheckAllowed 登录前检查账号和 IP 是否处于锁定状态，锁定时返回 *LockedError
+func (ls *LoginThrottleService) CheckAllowed(username, ip string) error {
+	now := time.Now()
+	var throttles []model.LoginThrottle
+	if err := database.DB.
+		Where("(kind = ? AND identifier = ?) OR (kind = ? AND identifier = ?)", ThrottleKindUser, strings.ToLower(username), ThrottleKindIP, ip).
+		Where("locked_until > ?", now).
+		Find(&throttles).Error; err != nil {
+		return err
+	}
+
+	if len(throttles) > 0 {
+		return &LockedError{Kind: throttles[0].Kind, LockedUntil: *throttles[0].LockedUntil}
+	}
+	return nil
+}
+
+// RecordFailure 记录一次登录失败，达到上限时锁定账号或 IP，账号被锁定时邮件通知用户
+// 不存在的用户名只计入 IP 限制，避免随意提交的用户名不断产生账号记录
+func (ls *LoginThrottleService) RecordFailure(username, ip string) {
+	exists, err := ls.accountExists(username)
+	if err != nil {
+		log.Printf("查询用户 %s 失败: %v", username, err)
+	}
+	if exists {
+		if locked, until, err := ls.recordFailure(ThrottleKindUser, strings.ToLower(username)); err != nil {
+			log.Printf("记录账号 %s 登录失败次数失败: %v", username, err)
+		} else if locked {
+			log.Printf("账号 %s 连续登录失败，锁定至 %s", username, until.Format("2006-01-02 15:04:05"))
+			go ls.notifyLocked(username, until)
+		}
+	}
+
+	if locked, until, err := ls.recordFailure(ThrottleKindIP, ip); err != nil {
+		log.Printf("记录 IP %s 登录失败次数失败: %v", ip, err)
+	} else if locked {
+		log.Printf("IP %s 登录失败过于频繁，限制至 %s", ip, until.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// RecordSuccess 登录成功后清除账号的失败计数和锁定记录
+// IP 计数不清除，避免攻击者用自己的账号重置同一 IP 上的计数
+func (ls *LoginThrottleService) RecordSuccess(username string) {
+	if err := database.DB.Where("kind = ? AND identifier = ?", ThrottleKindUser, strings.ToLower(username)).
+		Delete(&model.LoginThrottle{}).Error; err != nil {
+		log.Printf("清除账号 %s 登录失败记录失败: %v", username, err)
+	}
+}
+
+// AllowResetRequest 记录一次找回密码请求，同一用户名（或邮箱）和同一 IP 超过窗口内限额时返回 *LockedError
+func (ls *LoginThrottleService) AllowResetRequest(identifier, ip string) error {
+	if err := ls.hitWindow(ThrottleKindResetIP, ip, resetIPMaxRequests, resetWindow); err != nil {
+		return err
+	}
+	return ls.hitWindow(ThrottleKindResetUser, strings.ToLower(identifier), resetUserMaxRequests, resetWindow)
+}
+
+// hitWindow 在固定时间窗口内计数一次请求，窗口内已达上限时拒绝且不再计数
+func (ls *LoginThrottleService) hitWindow(kind, identifier string, max int, window time.Duration) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginThrottle{Kind: kind, Identifier: identifier}).Error; err != nil {
+			return err
+		}
+
+		var throttle model.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("kind = ? AND identifier = ?", kind, identifier).First(&throttle).Error; err != nil {
+			return err
+		}
+		if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+			return &LockedError{Kind: kind, LockedUntil: *throttle.LockedUntil}
+		}
+
+		applyWindow(&throttle, now, max, window)
+		return tx.Model(&throttle).Select("failed_count", "locked_until", "last_failed_at", "window_started_at").Updates(&throttle).Error
+	})
+}
+
+// LoginThrottleListSpec 登录限制记录列表允许的排序和筛选条件
+var LoginThrottleListSpec = ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":             "id",
+		"failed_count":   "failed_count",
+		"locked_until":   "locked_until",
+		"last_failed_at": "last_failed_at",
+		"updated_at":     "updated_at",
+	},
+	Filters: map[string]string{
+		"kind": "kind = ?",
+	},
+	KeywordColumns: []string{"identifier"},
+}
+
+// ListThrottles 按条件查询登录限制记录，lockedOnly 为 true 时只返回锁定中的记录，分页时同时返回总数
+func (ls *LoginThrottleService) ListThrottles(q *ListQuery, lockedOnly bool) ([]model.LoginThrottle, int64, error) {
+	query := database.DB.Model(&model.LoginThrottle{})
+	if lockedOnly {
+		query = query.Where("locked_until > ?", time.Now())
+	}
+	query, total, err := q.Apply(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	var throttles []model.LoginThrottle
+	err = query.Find(&throttles).Error
+	return throttles, total, err
+}
+
+// CleanupExpired 删除未处于锁定状态且超过锁定次数清零时间没有失败的记录，返回删除条数
+// 超过该时间后账号的失败次数和锁定次数都会重新计算，IP 和找回密码的时间窗口也已结束
+func (ls *LoginThrottleService) CleanupExpired() (int64, error) {
+	now := time.Now()
+	result := database.DB.
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Where("last_failed_at IS NULL OR last_failed_at < ?", now.Add(-lockoutCountReset)).
+		Delete(&model.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
+
+// ClearThrottle 管理员解除锁定并清零失败次数
+func (ls *LoginThrottleService) ClearThrottle(id uint) error {
+	result := database.DB.Delete(&model.LoginThrottle{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// recordFailure 在事务中累加失败次数，返回本次是否触发锁定及锁定截止时间
+func (ls *LoginThrottleService) recordFailure(kind, identifier string) (bool, time.Time, error) {
+	var locked bool
+	var lockedUntil time.Time
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginThrottle{Kind: kind, Identifier: identifier}).Error; err != nil {
+			return err
+		}
+
+		var throttle model.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("kind = ? AND identifier = ?", kind, identifier).First(&throttle).Error; err != nil {
+			return err
+		}
+
+		if kind == ThrottleKindIP {
+			locked, lockedUntil = applyWindow(&throttle, now, ipMaxFailures, ipWindow)
+			return tx.Model(&throttle).Select("failed_count", "locked_until", "last_failed_at", "window_started_at").Updates(&throttle).Error
+		}
+
+		// 长时间没有失败时重新计数
+		if throttle.LastFailedAt != nil {
+			idle := now.Sub(*throttle.LastFailedAt)
+			if idle > failureWindow {
+				throttle.FailedCount = 0
+			}
+			if idle > lockoutCountReset {
+				throttle.LockoutCount = 0
+			}
+		}
+
+		throttle.FailedCount++
+		throttle.LastFailedAt = &now
+		if throttle.FailedCount >= userMaxFailures {
+			lockedUntil = now.Add(lockoutDuration(throttle.LockoutCount))
+			throttle.LockedUntil = &lockedUntil
+			throttle.LockoutCount++
+			throttle.FailedCount = 0
+			locked = true
+		}
+
+		return tx.Model(&throttle).Select("failed_count", "lockout_count", "locked_until", "last_failed_at").Updates(&throttle).Error
+	})
+	return locked, lockedUntil, err
+}
+
+// applyWindow 在固定时间窗口内累计次数，达到上限时限制到窗口结束，不累计锁定次数
+func applyWindow(throttle *model.LoginThrottle, now time.Time, max int, window time.Duration) (bool, time.Time) {
+	if throttle.WindowStartedAt == nil || now.Sub(*throttle.WindowStartedAt) >= window {
+		throttle.WindowStartedAt = &now
+		throttle.FailedCount = 0
+	}
+	throttle.FailedCount++
+	throttle.LastFailedAt = &now
+	if throttle.FailedCount < max {
+		return false, time.Time{}
+	}
+	lockedUntil := throttle.WindowStartedAt.Add(window)
+	throttle.LockedUntil = &lockedUntil
+	return true, lockedUntil
+}
+
+// lockoutDuration 第 n 次（从 0 开始）锁定的时长
+func lockoutDuration(previousLockouts int) time.Duration {
+	duration := baseLockout
+	for i := 0; i < previousLockouts && duration < maxLockout; i++ {
+		duration *= 2
+	}
+	if duration > maxLockout {
+		duration = maxLockout
+	}
+	return duration
+}
+
+// accountExists 判断用户名是否对应一个未删除的账号
+func (ls *LoginThrottleService) accountExists(username string) (bool, error) {
+	var count int64
+	err := database.DB.Model(&model.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
+// notifyLocked 账号被锁定时邮件通知用户，用户不存在或没有邮箱时忽略
+func (ls *LoginThrottleService) notifyLocked(username string, until time.Time) {
+	var user model.User
+	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("查询用户 %s 失败: %v", username, err)
+		}
+		return
+	}
+	if user.Email == "" {
+		return
+	}
+
+	err := sendLoggedEmail(ls.getMailer(), "account_locked", user, mailer.Message{
+		To:      []string{user.Email},
+		Subject: "考勤系统账号已被临时锁定",
+		Text: fmt.Sprintf("%s，您好：\n\n您的账号（%s）因连续多次密码错误，已被临时锁定至 %s。\n\n如果不是您本人操作，建议在解锁后立即修改密码，或联系管理员处理。\n",
+			user.Name, user.Username, until.Format("2006-01-02 15:04:05")),
+	})
+	if err != nil {
+		log.Printf("发送账号锁定通知失败: %v", err)
+	}
+}
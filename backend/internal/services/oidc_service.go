@@ -0,0 +1,362 @@
+package services
+
+import (
+	"backend/config"
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	// OIDCLoginTTL 从跳转到身份提供方到回调的最长时间
+	OIDCLoginTTL = 10 * time.Minute
+	// OIDCExchangeTTL 回调后前端使用换取码获取令牌的最长时间
+	OIDCExchangeTTL = time.Minute
+)
+
+var (
+	// ErrOIDCDisabled 未配置单点登录
+	ErrOIDCDisabled = errors.New("未启用单点登录")
+	// ErrInvalidOIDCState state 或换取码不存在、已过期或已被使用
+	ErrInvalidOIDCState = errors.New("登录已过期，请重新登录")
+	// ErrOIDCAccountUnavailable 身份提供方认证通过，但无法关联或开通本系统账号
+	ErrOIDCAccountUnavailable = errors.New("该账号无法登录本系统，请联系管理员")
+)
+
+// OIDCService OpenID Connect 单点登录服务（授权码模式 + PKCE）
+type OIDCService struct {
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// Enabled 是否配置了单点登录
+func (s *OIDCService) Enabled() bool {
+	return config.Cfg.OIDCIssuer != ""
+}
+
+// getProvider 通过发现文档获取身份提供方配置，成功后缓存
+func (s *OIDCService) getProvider(ctx context.Context) (*oidc.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, config.Cfg.OIDCIssuer)
+		if err != nil {
+			return nil, fmt.Errorf("获取身份提供方配置失败: %w", err)
+		}
+		s.provider = provider
+	}
+	return s.provider, nil
+}
+
+// oauth2Config 授权码模式的客户端配置
+func (s *OIDCService) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     config.Cfg.OIDCClientID,
+		ClientSecret: config.Cfg.OIDCClientSecret,
+		RedirectURL:  config.Cfg.OIDCRedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       strings.Fields(config.Cfg.OIDCScopes),
+	}
+}
+
+// BeginLogin 生成 state、nonce 和 PKCE code_verifier，返回身份提供方的授权地址
+func (s *OIDCService) BeginLogin(ctx context.Context) (string, error) {
+	if !s.Enabled() {
+		return "", ErrOIDCDisabled
+	}
+	provider, err := s.getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	login := model.OIDCLogin{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OIDCLoginTTL),
+	}
+	if err := database.DB.Create(&login).Error; err != nil {
+		return "", err
+	}
+
+	return s.oauth2Config(provider).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// HandleCallback 校验 state，用授权码换取并验证 ID Token，关联或开通用户后返回一次性换取码
+func (s *OIDCService) HandleCallback(ctx context.Context, state, code string) (string, error) {
+	if !s.Enabled() {
+		return "", ErrOIDCDisabled
+	}
+	provider, err := s.getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var login model.OIDCLogin
+	if err := database.DB.Where("state_hash = ?", hashToken(state)).First(&login).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrInvalidOIDCState
+		}
+		return "", err
+	}
+	if login.CallbackAt != nil || time.Now().After(login.ExpiresAt) {
+		return "", ErrInvalidOIDCState
+	}
+	// 条件更新保证 state 只能使用一次
+	result := database.DB.Model(&model.OIDCLogin{}).
+		Where("id = ? AND callback_at IS NULL", login.ID).
+		Update("callback_at", time.Now())
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", ErrInvalidOIDCState
+	}
+
+	token, err := s.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(login.CodeVerifier))
+	if err != nil {
+		return "", fmt.Errorf("授权码换取令牌失败: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", errors.New("身份提供方未返回 ID Token")
+	}
+
+	// 校验签名、签发者、受众和有效期
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: config.Cfg.OIDCClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", fmt.Errorf("ID Token 校验失败: %w", err)
+	}
+	if idToken.Nonce != login.Nonce {
+		return "", errors.New("ID Token nonce 不匹配")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return "", err
+	}
+	user, err := s.linkUser(idToken.Issuer, idToken.Subject, claims)
+	if err != nil {
+		return "", err
+	}
+
+	exchangeCode, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := database.DB.Model(&login).Updates(map[string]interface{}{
+		"user_id":            user.ID,
+		"exchange_code_hash": hashToken(exchangeCode),
+	}).Error; err != nil {
+		return "", err
+	}
+	return exchangeCode, nil
+}
+
+// Exchange 使用回调后得到的一次性换取码获取登录用户
+func (s *OIDCService) Exchange(exchangeCode string) (*model.User, error) {
+	var login model.OIDCLogin
+	if err := database.DB.Where("exchange_code_hash = ?", hashToken(exchangeCode)).First(&login).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	if login.UserID == nil || login.ExchangedAt != nil || login.CallbackAt == nil ||
+		time.Now().After(login.CallbackAt.Add(OIDCExchangeTTL)) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	result := database.DB.Model(&model.OIDCLogin{}).
+		Where("id = ? AND exchanged_at IS NULL", login.ID).
+		Update("exchanged_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidOIDCState
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, *login.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOIDCAccountUnavailable
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// linkUser 按已关联的身份查找用户；首次登录时依次按用户名、已验证的邮箱关联已有账号，都没有时开通新账号
+// 管理员账号不会被自动关联
+func (s *OIDCService) linkUser(issuer, subject string, claims map[string]interface{}) (*model.User, error) {
+	profile := s.profile(claims)
+
+	var identity model.UserIdentity
+	err := database.DB.Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if err == nil {
+		var user model.User
+		if err := database.DB.First(&user, identity.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrOIDCAccountUnavailable
+			}
+			return nil, err
+		}
+		if err := syncExternalProfile(&user, AuthSourceOIDC, profile); err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var user *model.User
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing model.User
+		found, usernameTaken := false, false
+		if profile.Username != "" {
+			var byUsername model.User
+			err := tx.Unscoped().Where("username = ?", profile.Username).First(&byUsername).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			usernameTaken = err == nil
+			// 用户名声明可能由用户自行修改，默认不按用户名关联已有账号
+			if usernameTaken && config.Cfg.OIDCLinkUsername && !byUsername.DeletedAt.Valid {
+				existing, found = byUsername, true
+			}
+		}
+		if !found && profile.Email != "" && claimBool(claims, "email_verified") {
+			err := tx.Where("email = ?", profile.Email).First(&existing).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			found = err == nil
+		}
+
+		switch {
+		case !found && usernameTaken:
+			log.Printf("单点登录身份 %s 的用户名 %s 已被其他账号占用，拒绝自动关联", subject, profile.Username)
+			return ErrOIDCAccountUnavailable
+		case found && existing.Role == "admin":
+			log.Printf("单点登录身份 %s 对应管理员账号 %s，拒绝自动关联", subject, existing.Username)
+			return ErrOIDCAccountUnavailable
+		case found:
+			user = &existing
+		case profile.Username == "":
+			return ErrOIDCAccountUnavailable
+		default:
+			created, err := createExternalUser(tx, AuthSourceOIDC, profile)
+			if err != nil {
+				if errors.Is(err, ErrInvalidCredentials) {
+					log.Printf("单点登录用户 %s 无法映射到系统角色，拒绝开通", profile.Username)
+					return ErrOIDCAccountUnavailable
+				}
+				return err
+			}
+			user = created
+		}
+
+		return tx.Create(&model.UserIdentity{UserID: user.ID, Issuer: issuer, Subject: subject}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("单点登录身份 %s 已关联到用户 %s", subject, user.Username)
+	return user, nil
+}
+
+// CleanupExpiredLogins 删除已超过回调和换取期限的单点登录记录，返回删除条数
+func (s *OIDCService) CleanupExpiredLogins() (int64, error) {
+	result := database.DB.Where("expires_at < ?", time.Now().Add(-OIDCExchangeTTL)).Delete(&model.OIDCLogin{})
+	return result.RowsAffected, result.Error
+}
+
+// profile 按配置的声明名称读取用户信息
+func (s *OIDCService) profile(claims map[string]interface{}) *ExternalProfile {
+	cfg := config.Cfg
+	profile := &ExternalProfile{
+		Username: claimString(claims, cfg.OIDCUsernameClaim),
+		Name:     claimString(claims, cfg.OIDCNameClaim),
+		Email:    claimString(claims, cfg.OIDCEmailClaim),
+		Role:     mapExternalRole(parseRoleMap(cfg.OIDCRoleMap), claimStrings(claims, cfg.OIDCRoleClaim)),
+	}
+	if profile.Role == "" && isExternalRole(cfg.OIDCDefaultRole) {
+		profile.Role = cfg.OIDCDefaultRole
+	}
+	return profile
+}
+
+// syncExternalProfile 同步外部身份源中的姓名和邮箱，只更新该来源开通的账号，角色以本系统为准
+func syncExternalProfile(user *model.User, source string, profile *ExternalProfile) error {
+	if user.AuthSource != source {
+		return nil
+	}
+	updates := map[string]interface{}{}
+	if profile.Name != "" {
+		updates["name"] = profile.Name
+	}
+	if profile.Email != "" {
+		updates["email"] = profile.Email
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return database.DB.Model(user).Updates(updates).Error
+}
+
+// claimString 读取字符串类型的声明
+func claimString(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings 读取字符串或字符串数组类型的声明
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// claimBool 读取布尔类型的声明，部分身份提供方以字符串表示
+func claimBool(claims map[string]interface{}, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
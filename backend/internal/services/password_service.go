@@ -0,0 +1,166 @@
+package services
+
+import (
+	"backend/config"
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/mailer"
+	"backend/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// PasswordResetTTL 找回密码令牌有效期
+	PasswordResetTTL = 30 * time.Minute
+	// PasswordChangeTTL 首次登录强制修改密码令牌的有效期
+	PasswordChangeTTL = 15 * time.Minute
+	// PurposePasswordChange 必须修改初始密码的用户登录后只能用于修改密码的令牌用途
+	PurposePasswordChange = "password_change"
+)
+
+var (
+	// ErrWrongPassword 当前密码错误
+	ErrWrongPassword = errors.New("当前密码错误")
+	// ErrInvalidResetToken 找回密码令牌不存在、已过期或已被使用
+	ErrInvalidResetToken = errors.New("重置链接无效或已过期，请重新申请")
+	// ErrPasswordChangeNotRequired 用户已修改过初始密码，强制改密令牌不再可用
+	ErrPasswordChangeNotRequired = errors.New("密码已修改，请重新登录")
+)
+
+// PasswordService 用户自助修改密码和找回密码
+type PasswordService struct {
+	mailer      mailer.Mailer
+	mailerOnce  sync.Once
+	userService UserService
+}
+
+// getMailer 未指定发送器时使用配置中的 SMTP 服务
+// 服务实例在定时任务和请求处理的多个 goroutine 间共享，只初始化一次
+func (ps *PasswordService) getMailer() mailer.Mailer {
+	ps.mailerOnce.Do(func() {
+		if ps.mailer == nil {
+			ps.mailer = mailer.NewSMTPMailer(config.Cfg)
+		}
+	})
+	return ps.mailer
+}
+
+// ChangePassword 校验当前密码后修改密码，已签发的令牌随之失效
+func (ps *PasswordService) ChangePassword(userID uint, currentPassword, newPassword string) error {
+	user, err := ps.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.AuthSource != AuthSourceLocal {
+		return ErrExternalAccount
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.PasswordHash) {
+		return ErrWrongPassword
+	}
+	if currentPassword == newPassword {
+		return errors.New("新密码不能与当前密码相同")
+	}
+	return ps.userService.UpdatePassword(userID, newPassword, false)
+}
+
+// CompleteRequiredChange 首次登录或管理员重置后修改初始密码，调用方已通过专用令牌确认身份
+func (ps *PasswordService) CompleteRequiredChange(userID uint, newPassword string) error {
+	user, err := ps.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.MustChangePassword {
+		return ErrPasswordChangeNotRequired
+	}
+	if utils.CheckPasswordHash(newPassword, user.PasswordHash) {
+		return errors.New("新密码不能与初始密码相同")
+	}
+	return ps.userService.UpdatePassword(userID, newPassword, false)
+}
+
+// RequestReset 按用户名或邮箱查找用户并发送找回密码邮件
+// 用户不存在或未设置邮箱时静默返回，避免通过接口探测账号
+func (ps *PasswordService) RequestReset(identifier string) error {
+	var user model.User
+	err := database.DB.Where("username = ? OR email = ?", identifier, identifier).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.AuthSource != AuthSourceLocal {
+		log.Printf("用户 %d 使用统一身份认证登录，不发送找回密码邮件", user.ID)
+		return nil
+	}
+	if user.Email == "" {
+		log.Printf("用户 %d 未设置邮箱，无法发送找回密码邮件", user.ID)
+		return nil
+	}
+
+	token, err := generateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 新令牌生效后，之前未使用的令牌全部作废
+		if err := tx.Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashToken(token),
+			ExpiresAt: time.Now().Add(PasswordResetTTL),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := config.Cfg.FrontendURL + "/reset-password?token=" + url.QueryEscape(token)
+	minutes := int(PasswordResetTTL.Minutes())
+	return sendLoggedEmail(ps.getMailer(), "password_reset", user, mailer.Message{
+		To:      []string{user.Email},
+		Subject: "重置考勤系统密码",
+		Text: fmt.Sprintf("%s，您好：\n\n我们收到了重置您账号（%s）密码的请求。请在 %d 分钟内打开以下链接设置新密码：\n\n%s\n\n如果不是您本人操作，请忽略此邮件。\n",
+			user.Name, user.Username, minutes, link),
+	})
+}
+
+// ResetPassword 使用找回密码令牌设置新密码，令牌只能使用一次
+func (ps *PasswordService) ResetPassword(token, newPassword string) error {
+	var resetToken model.PasswordResetToken
+	if err := database.DB.Where("token_hash = ?", hashToken(token)).First(&resetToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	// 条件更新保证并发请求中只有一个能使用该令牌，修改密码失败时令牌不被消耗
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+		return updatePassword(tx, resetToken.UserID, newPassword, false)
+	})
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportService 考勤统计报表服务
+type ReportService struct{}
+
+// StudentAttendanceSummary 学生在某门课程中的考勤汇总
+type StudentAttendanceSummary struct {
+	StudentID       uint       `json:"student_id"`
+	Username        string     `json:"username"`
+	StudentName     string     `json:"student_name"`
+	TotalSessions   int        `json:"total_sessions"`
+	Present         int        `json:"present"`
+	Late            int        `json:"late"`
+	Absent          int        `json:"absent"`
+	Excused         int        `json:"excused"`
+	AttendanceRate  float64    `json:"attendance_rate"`
+	LastAbsenceDate *time.Time `json:"last_absence_date"`
+}
+
+// DateRange 报表统计的时间范围，零值表示不限制
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// apply 按会话开始时间过滤
+func (r DateRange) apply(query *gorm.DB, column string) *gorm.DB {
+	if !r.Start.IsZero() {
+		query = query.Where(column+" >= ?", r.Start)
+	}
+	if !r.End.IsZero() {
+		query = query.Where(column+" < ?", r.End)
+	}
+	return query
+}
+
+// attendanceRate 计算出勤率：(出勤 + 迟到) / (总次数 - 请假)，无有效会话时为 0
+func attendanceRate(present, late, excused, total int) float64 {
+	denominator := total - excused
+	if denominator <= 0 {
+		return 0
+	}
+	return float64(present+late) / float64(denominator)
+}
+
+// AttendanceMatrix 课程考勤矩阵：行为选课学生，列为签到会话
+type AttendanceMatrix struct {
+	Course   model.Course
+	Sessions []model.CheckinSession
+	Rows     []AttendanceMatrixRow
+}
+
+// AttendanceMatrixRow 考勤矩阵中的一行，Statuses 与 Sessions 一一对应
+type AttendanceMatrixRow struct {
+	StudentAttendanceSummary
+	Statuses []string
+}
+
+// GetCourseStudentReport 统计课程中每个学生的出勤、迟到、缺勤和请假次数
+func (rs *ReportService) GetCourseStudentReport(courseID uint, dateRange DateRange) ([]StudentAttendanceSummary, error) {
+	matrix, err := rs.GetCourseAttendanceMatrix(courseID, dateRange)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StudentAttendanceSummary, 0, len(matrix.Rows))
+	for _, row := range matrix.Rows {
+		result = append(result, row.StudentAttendanceSummary)
+	}
+	return result, nil
+}
+
+// GetCourseAttendanceMatrix 构建课程考勤矩阵
+// 只统计已结束的签到会话，未签到的学生计为缺勤
+func (rs *ReportService) GetCourseAttendanceMatrix(courseID uint, dateRange DateRange) (*AttendanceMatrix, error) {
+	var course model.Course
+	if err := database.DB.Preload("Teacher").First(&course, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("课程不存在")
+		}
+		return nil, err
+	}
+
+	var sessions []model.CheckinSession
+	query := database.DB.Where("course_id = ? AND status = ?", courseID, "ended")
+	if err := dateRange.apply(query, "start_time").Order("start_time").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	var enrollments []model.Enrollment
+	if err := database.DB.Preload("Student").Where("course_id = ?", courseID).Find(&enrollments).Error; err != nil {
+		return nil, err
+	}
+
+	// 按 会话ID -> 学生ID 索引签到状态
+	statusMap := make(map[uint]map[uint]string, len(sessions))
+	if len(sessions) > 0 {
+		sessionIDs := make([]uint, 0, len(sessions))
+		for _, session := range sessions {
+			sessionIDs = append(sessionIDs, session.ID)
+			statusMap[session.ID] = make(map[uint]string)
+		}
+
+		var records []model.CheckinRecord
+		if err := database.DB.Select("session_id", "student_id", "status").Where("session_id IN ?", sessionIDs).Find(&records).Error; err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			statusMap[record.SessionID][record.StudentID] = record.Status
+		}
+	}
+
+	matrix := &AttendanceMatrix{
+		Course:   course,
+		Sessions: sessions,
+		Rows:     make([]AttendanceMatrixRow, 0, len(enrollments)),
+	}
+	for _, enrollment := range enrollments {
+		row := AttendanceMatrixRow{
+			StudentAttendanceSummary: StudentAttendanceSummary{
+				StudentID:     enrollment.StudentID,
+				Username:      enrollment.Student.Username,
+				StudentName:   enrollment.Student.Name,
+				TotalSessions: len(sessions),
+			},
+			Statuses: make([]string, 0, len(sessions)),
+		}
+
+		for _, session := range sessions {
+			status, exists := statusMap[session.ID][enrollment.StudentID]
+			if !exists {
+				status = "absent"
+			}
+			row.Statuses = append(row.Statuses, status)
+
+			switch status {
+			case "present":
+				row.Present++
+			case "late":
+				row.Late++
+			case "excused":
+				row.Excused++
+			default:
+				row.Absent++
+				startTime := session.StartTime
+				row.LastAbsenceDate = &startTime
+			}
+		}
+
+		row.AttendanceRate = attendanceRate(row.Present, row.Late, row.Excused, row.TotalSessions)
+		matrix.Rows = append(matrix.Rows, row)
+	}
+
+	return matrix, nil
+}
+
+// SessionAttendancePoint 单次签到会话的考勤数据
+type SessionAttendancePoint struct {
+	SessionID      uint      `json:"session_id"`
+	SessionCode    string    `json:"session_code"`
+	StartTime      time.Time `json:"start_time"`
+	Enrolled       int       `json:"enrolled"`
+	Present        int       `json:"present"`
+	Late           int       `json:"late"`
+	Absent         int       `json:"absent"`
+	Excused        int       `json:"excused"`
+	AttendanceRate float64   `json:"attendance_rate"`
+}
+
+// WeeklyAttendancePoint 按周汇总的考勤数据，Enrolled 为该周各会话应到人数之和
+type WeeklyAttendancePoint struct {
+	Week           string    `json:"week"`
+	WeekStart      time.Time `json:"week_start"`
+	Sessions       int       `json:"sessions"`
+	Enrolled       int       `json:"enrolled"`
+	Present        int       `json:"present"`
+	Late           int       `json:"late"`
+	Absent         int       `json:"absent"`
+	Excused        int       `json:"excused"`
+	AttendanceRate float64   `json:"attendance_rate"`
+}
+
+// sessionStatusCount 按会话和状态分组的签到记录数
+type sessionStatusCount struct {
+	SessionID uint
+	Status    string
+	Count     int
+}
+
+// GetCourseAttendanceTrend 获取课程每次签到会话的考勤数据，会话可见范围与签到会话列表一致
+func (rs *ReportService) GetCourseAttendanceTrend(courseID, userID uint, role string, dateRange DateRange) ([]SessionAttendancePoint, error) {
+	query := database.DB.Where("course_id = ? AND status = ?", courseID, "ended")
+	return rs.attendanceTrend(courseID, ScopeSessionsByRole(dateRange.apply(query, "start_time"), userID, role))
+}
+
+// courseAttendanceTrend 获取课程全部签到会话的考勤数据，不按角色限制范围，供周报等内部任务使用
+func (rs *ReportService) courseAttendanceTrend(courseID uint, dateRange DateRange) ([]SessionAttendancePoint, error) {
+	query := database.DB.Where("course_id = ? AND status = ?", courseID, "ended")
+	return rs.attendanceTrend(courseID, dateRange.apply(query, "start_time"))
+}
+
+// attendanceTrend 按会话汇总考勤数据，query 为已筛选好的签到会话查询
+func (rs *ReportService) attendanceTrend(courseID uint, query *gorm.DB) ([]SessionAttendancePoint, error) {
+	var sessions []model.CheckinSession
+	if err := query.Order("start_time").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]SessionAttendancePoint, 0, len(sessions))
+	if len(sessions) == 0 {
+		return result, nil
+	}
+
+	var enrolled int64
+	if err := database.DB.Model(&model.Enrollment{}).Where("course_id = ?", courseID).Count(&enrolled).Error; err != nil {
+		return nil, err
+	}
+
+	sessionIDs := make([]uint, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIDs = append(sessionIDs, session.ID)
+	}
+
+	var counts []sessionStatusCount
+	if err := database.DB.Model(&model.CheckinRecord{}).
+		Select("session_id, status, COUNT(*) AS count").
+		Where("session_id IN ?", sessionIDs).
+		Group("session_id, status").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	countMap := make(map[uint]map[string]int, len(sessions))
+	for _, count := range counts {
+		if countMap[count.SessionID] == nil {
+			countMap[count.SessionID] = make(map[string]int)
+		}
+		countMap[count.SessionID][count.Status] = count.Count
+	}
+
+	for _, session := range sessions {
+		point := SessionAttendancePoint{
+			SessionID:   session.ID,
+			SessionCode: session.SessionCode,
+			StartTime:   session.StartTime,
+			Enrolled:    int(enrolled),
+			Present:     countMap[session.ID]["present"],
+			Late:        countMap[session.ID]["late"],
+			Excused:     countMap[session.ID]["excused"],
+		}
+		// 未签到的选课学生计为缺勤
+		point.Absent = point.Enrolled - point.Present - point.Late - point.Excused
+		if point.Absent < 0 {
+			point.Absent = 0
+		}
+		point.AttendanceRate = attendanceRate(point.Present, point.Late, point.Excused, point.Enrolled)
+		result = append(result, point)
+	}
+
+	return result, nil
+}
+
+// AggregateAttendanceByWeek 将会话考勤数据按自然周（周一开始）汇总
+func AggregateAttendanceByWeek(points []SessionAttendancePoint) []WeeklyAttendancePoint {
+	result := make([]WeeklyAttendancePoint, 0)
+	index := make(map[string]int)
+
+	for _, point := range points {
+		year, week := point.StartTime.ISOWeek()
+		key := fmt.Sprintf("%d-W%02d", year, week)
+
+		i, exists := index[key]
+		if !exists {
+			start := point.StartTime
+			offset := (int(start.Weekday()) + 6) % 7
+			weekStart := time.Date(start.Year(), start.Month(), start.Day()-offset, 0, 0, 0, 0, start.Location())
+			result = append(result, WeeklyAttendancePoint{Week: key, WeekStart: weekStart})
+			i = len(result) - 1
+			index[key] = i
+		}
+
+		result[i].Sessions++
+		result[i].Enrolled += point.Enrolled
+		result[i].Present += point.Present
+		result[i].Late += point.Late
+		result[i].Absent += point.Absent
+		result[i].Excused += point.Excused
+	}
+
+	for i := range result {
+		result[i].AttendanceRate = attendanceRate(result[i].Present, result[i].Late, result[i].Excused, result[i].Enrolled)
+	}
+
+	return result
+}
+
+// ArrivalBucket 签到时间分布的一个区间，单位为秒（相对签到开始时间）
+type ArrivalBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+// ArrivalDistribution 签到时间分布
+type ArrivalDistribution struct {
+	Sessions      int                `json:"sessions"`
+	Checkins      int                `json:"checkins"`
+	BucketSeconds int                `json:"bucket_seconds"`
+	Buckets       []ArrivalBucket    `json:"buckets"`
+	Percentiles   map[string]float64 `json:"percentiles"`
+	LastMinute    int                `json:"last_minute"` // 在签到窗口最后一分钟内签到的人数
+}
+
+// arrivalSample 一条签到记录及其所在会话的开始时间和时长
+type arrivalSample struct {
+	SessionID   uint
+	CheckinTime time.Time
+	StartTime   time.Time
+	Duration    int
+}
+
+// GetSessionArrivalDistribution 统计单次签到的到达时间分布
+func (rs *ReportService) GetSessionArrivalDistribution(sessionID uint, bucketSeconds int) (*ArrivalDistribution, error) {
+	query := database.DB.Where("checkin_sessions.id = ?", sessionID)
+	return rs.arrivalDistribution(query, bucketSeconds)
+}
+
+// GetCourseArrivalDistribution 汇总课程内已结束签到的到达时间分布
+func (rs *ReportService) GetCourseArrivalDistribution(courseID uint, dateRange DateRange, bucketSeconds int) (*ArrivalDistribution, error) {
+	query := database.DB.Where("checkin_sessions.course_id = ? AND checkin_sessions.status = ?", courseID, "ended")
+	return rs.arrivalDistribution(dateRange.apply(query, "checkin_sessions.start_time"), bucketSeconds)
+}
+
+// arrivalDistribution 统计签到时间与开始时间之差的直方图和百分位数
+// 只统计签到窗口内的出勤和迟到记录，窗口外的记录一般为教师补签，不反映学生到达时间
+func (rs *ReportService) arrivalDistribution(sessionQuery *gorm.DB, bucketSeconds int) (*ArrivalDistribution, error) {
+	var samples []arrivalSample
+	if err := database.DB.Model(&model.CheckinRecord{}).
+		Select("checkin_records.session_id, checkin_records.checkin_time, checkin_sessions.start_time, checkin_sessions.duration").
+		Joins("JOIN checkin_sessions ON checkin_sessions.id = checkin_records.session_id AND checkin_sessions.deleted_at IS NULL").
+		Where("checkin_records.status IN ?", []string{"present", "late"}).
+		Where(sessionQuery).
+		Scan(&samples).Error; err != nil {
+		return nil, err
+	}
+
+	result := &ArrivalDistribution{
+		BucketSeconds: bucketSeconds,
+		Buckets:       make([]ArrivalBucket, 0),
+		Percentiles:   make(map[string]float64),
+	}
+
+	sessionSet := make(map[uint]bool)
+	offsets := make([]float64, 0, len(samples))
+	maxWindow := 0
+	for _, sample := range samples {
+		window := sample.Duration * 60
+		offset := sample.CheckinTime.Sub(sample.StartTime).Seconds()
+		if offset < 0 || offset > float64(window) {
+			continue
+		}
+
+		sessionSet[sample.SessionID] = true
+		offsets = append(offsets, offset)
+		if window > maxWindow {
+			maxWindow = window
+		}
+		if offset >= float64(window-60) {
+			result.LastMinute++
+		}
+	}
+	result.Sessions = len(sessionSet)
+	result.Checkins = len(offsets)
+	if len(offsets) == 0 {
+		return result, nil
+	}
+
+	for from := 0; from < maxWindow; from += bucketSeconds {
+		result.Buckets = append(result.Buckets, ArrivalBucket{From: from, To: from + bucketSeconds})
+	}
+	// 时长为 0 的会话（开始后立即手动结束）没有完整的分组，签到全部计入第一组
+	if len(result.Buckets) == 0 {
+		result.Buckets = append(result.Buckets, ArrivalBucket{From: 0, To: bucketSeconds})
+	}
+	for _, offset := range offsets {
+		i := int(offset) / bucketSeconds
+		if i >= len(result.Buckets) {
+			i = len(result.Buckets) - 1
+		}
+		result.Buckets[i].Count++
+	}
+
+	sort.Float64s(offsets)
+	for _, p := range []int{50, 75, 90, 95} {
+		result.Percentiles[fmt.Sprintf("p%d", p)] = percentile(offsets, float64(p))
+	}
+
+	return result, nil
+}
+
+// percentile 计算已排序数据的百分位数（线性插值）
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ScoringService 考勤成绩计算服务
+type ScoringService struct {
+	reportService ReportService
+}
+
+// DefaultScoringPolicy 课程未配置规则时使用的默认规则
+func DefaultScoringPolicy(courseID uint) model.ScoringPolicy {
+	return model.ScoringPolicy{
+		CourseID:      courseID,
+		FullScore:     10,
+		PresentWeight: 1,
+		LateWeight:    0.5,
+		AbsentWeight:  0,
+		ExcusedWeight: 1,
+	}
+}
+
+// StudentScore 学生考勤成绩及计算说明
+type StudentScore struct {
+	StudentAttendanceSummary
+	Score       float64 `json:"score"`
+	Explanation string  `json:"explanation"`
+}
+
+// GetPolicy 获取课程的成绩规则，未配置时返回默认规则
+func (ss *ScoringService) GetPolicy(courseID uint) (model.ScoringPolicy, error) {
+	var policy model.ScoringPolicy
+	err := database.DB.Where("course_id = ?", courseID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultScoringPolicy(courseID), nil
+	}
+	return policy, err
+}
+
+// SavePolicy 保存课程的成绩规则
+func (ss *ScoringService) SavePolicy(policy model.ScoringPolicy) (*model.ScoringPolicy, error) {
+	if policy.FullScore <= 0 {
+		return nil, errors.New("考勤满分必须大于 0")
+	}
+	for _, weight := range []float64{policy.PresentWeight, policy.LateWeight, policy.AbsentWeight, policy.ExcusedWeight} {
+		if weight < 0 || weight > 1 {
+			return nil, errors.New("状态权重应在 0 到 1 之间")
+		}
+	}
+	if policy.MaxAbsences < 0 {
+		return nil, errors.New("最大缺勤次数不能为负数")
+	}
+	if policy.Cap != nil && *policy.Cap < 0 {
+		return nil, errors.New("成绩上限不能为负数")
+	}
+
+	var existing model.ScoringPolicy
+	err := database.DB.Where("course_id = ?", policy.CourseID).First(&existing).Error
+	switch {
+	case err == nil:
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+		err = database.DB.Save(&policy).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = database.DB.Create(&policy).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetCourseScores 根据课程成绩规则和签到记录计算每个学生的考勤成绩
+func (ss *ScoringService) GetCourseScores(courseID uint) (*model.ScoringPolicy, []StudentScore, error) {
+	policy, err := ss.GetPolicy(courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summaries, err := ss.reportService.GetCourseStudentReport(courseID, DateRange{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scores := make([]StudentScore, 0, len(summaries))
+	for _, summary := range summaries {
+		score, explanation := calculateScore(policy, summary)
+		scores = append(scores, StudentScore{
+			StudentAttendanceSummary: summary,
+			Score:                    score,
+			Explanation:              explanation,
+		})
+	}
+	return &policy, scores, nil
+}
+
+// calculateScore 按规则计算成绩：满分 × Σ(各状态次数 × 权重) / 签到总次数
+func calculateScore(policy model.ScoringPolicy, summary StudentAttendanceSummary) (float64, string) {
+	if summary.TotalSessions == 0 {
+		return policy.FullScore, fmt.Sprintf("暂无已结束的签到，按满分 %s 计", formatScore(policy.FullScore))
+	}
+
+	if policy.MaxAbsences > 0 && summary.Absent > policy.MaxAbsences {
+		return 0, fmt.Sprintf("缺勤 %d 次，超过允许的 %d 次，考勤成绩为 0", summary.Absent, policy.MaxAbsences)
+	}
+
+	weighted := float64(summary.Present)*policy.PresentWeight +
+		float64(summary.Late)*policy.LateWeight +
+		float64(summary.Absent)*policy.AbsentWeight +
+		float64(summary.Excused)*policy.ExcusedWeight
+	score := policy.FullScore * weighted / float64(summary.TotalSessions)
+
+	explanation := fmt.Sprintf("%s × (出勤 %d×%s + 迟到 %d×%s + 缺勤 %d×%s + 请假 %d×%s) / %d 次签到 = %s",
+		formatScore(policy.FullScore),
+		summary.Present, formatScore(policy.PresentWeight),
+		summary.Late, formatScore(policy.LateWeight),
+		summary.Absent, formatScore(policy.AbsentWeight),
+		summary.Excused, formatScore(policy.ExcusedWeight),
+		summary.TotalSessions, formatScore(score))
+
+	if policy.Cap != nil && score > *policy.Cap {
+		score = *policy.Cap
+		explanation += fmt.Sprintf("，超过上限按 %s 计", formatScore(score))
+	}
+	// 保存规则时已限制权重不超过 1，这里兜底保证成绩不超过满分
+	if score > policy.FullScore {
+		score = policy.FullScore
+		explanation += fmt.Sprintf("，超过满分按 %s 计", formatScore(score))
+	}
+
+	return math.Round(score*100) / 100, explanation
+}
+
+// formatScore 格式化分数，去掉多余的小数位
+func formatScore(value float64) string {
+	s := fmt.Sprintf("%.2f", value)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return s
+}
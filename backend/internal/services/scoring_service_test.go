@@ -0,0 +1,60 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"testing"
+)
+
+func TestSavePolicyKeepsZeroWeights(t *testing.T) {
+	setupTestDB(t, &model.ScoringPolicy{})
+	ss := &ScoringService{}
+
+	// 新建和更新两条路径都要保留 0 权重
+	for _, step := range []string{"create", "update"} {
+		_, err := ss.SavePolicy(model.ScoringPolicy{
+			CourseID:      1,
+			FullScore:     10,
+			PresentWeight: 0,
+			LateWeight:    0,
+			AbsentWeight:  0,
+			ExcusedWeight: 0,
+			UpdatedBy:     1,
+		})
+		if err != nil {
+			t.Fatalf("%s: SavePolicy 失败: %v", step, err)
+		}
+
+		policy, err := ss.GetPolicy(1)
+		if err != nil {
+			t.Fatalf("%s: GetPolicy 失败: %v", step, err)
+		}
+		if policy.ID == 0 {
+			t.Fatalf("%s: 规则未保存", step)
+		}
+		weights := []float64{policy.PresentWeight, policy.LateWeight, policy.AbsentWeight, policy.ExcusedWeight}
+		for i, weight := range weights {
+			if weight != 0 {
+				t.Errorf("%s: 第 %d 个权重保存后为 %v，期望 0", step, i, weight)
+			}
+		}
+	}
+}
+
+func TestSavePolicyRejectsWeightAboveOne(t *testing.T) {
+	setupTestDB(t, &model.ScoringPolicy{})
+	ss := &ScoringService{}
+
+	_, err := ss.SavePolicy(model.ScoringPolicy{CourseID: 1, FullScore: 10, PresentWeight: 5, UpdatedBy: 1})
+	if err == nil {
+		t.Fatal("权重大于 1 时应返回错误")
+	}
+}
+
+func TestCalculateScoreNeverExceedsFullScore(t *testing.T) {
+	// 历史数据中可能存在大于 1 的权重
+	policy := model.ScoringPolicy{FullScore: 10, PresentWeight: 5}
+	score, _ := calculateScore(policy, StudentAttendanceSummary{TotalSessions: 2, Present: 2})
+	if score != 10 {
+		t.Errorf("成绩为 %v，期望不超过满分 10", score)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"time"
+)
+
+// StudentService 学生自助查询服务，所有查询均限定为学生本人
+type StudentService struct{}
+
+// StudentCourseSummary 学生在某门课程中的考勤汇总
+type StudentCourseSummary struct {
+	CourseID       uint    `json:"course_id"`
+	CourseCode     string  `json:"course_code"`
+	CourseName     string  `json:"course_name"`
+	TeacherName    string  `json:"teacher_name"`
+	Semester       string  `json:"semester"`
+	TotalSessions  int     `json:"total_sessions"`
+	Present        int     `json:"present"`
+	Late           int     `json:"late"`
+	Absent         int     `json:"absent"`
+	Excused        int     `json:"excused"`
+	AttendanceRate float64 `json:"attendance_rate"`
+}
+
+// StudentSessionStatus 学生在某次签到中的状态
+type StudentSessionStatus struct {
+	SessionID     uint       `json:"session_id"`
+	CourseID      uint       `json:"course_id"`
+	CourseName    string     `json:"course_name"`
+	StartTime     time.Time  `json:"start_time"`
+	Duration      int        `json:"duration"`
+	Status        string     `json:"status"` // present, late, absent, excused；进行中且未签到为 pending
+	CheckinTime   *time.Time `json:"checkin_time"`
+	SessionStatus string     `json:"session_status"` // active, ended
+}
+
+// GetAttendanceHistory 获取学生所选课程的全部签到会话及本人状态，courseID 为 0 时返回全部课程
+func (s *StudentService) GetAttendanceHistory(studentID, courseID uint) ([]StudentSessionStatus, error) {
+	query := database.DB.Preload("Course").
+		Where("course_id IN (?)", database.DB.Model(&model.Enrollment{}).Select("course_id").Where("student_id = ?", studentID))
+	if courseID != 0 {
+		query = query.Where("course_id = ?", courseID)
+	}
+
+	var sessions []model.CheckinSession
+	if err := query.Order("start_time DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]StudentSessionStatus, 0, len(sessions))
+	if len(sessions) == 0 {
+		return result, nil
+	}
+
+	sessionIDs := make([]uint, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIDs = append(sessionIDs, session.ID)
+	}
+
+	var records []model.CheckinRecord
+	if err := database.DB.Where("student_id = ? AND session_id IN ?", studentID, sessionIDs).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	recordMap := make(map[uint]model.CheckinRecord, len(records))
+	for _, record := range records {
+		recordMap[record.SessionID] = record
+	}
+
+	for _, session := range sessions {
+		item := StudentSessionStatus{
+			SessionID:     session.ID,
+			CourseID:      session.CourseID,
+			CourseName:    session.Course.Name,
+			StartTime:     session.StartTime,
+			Duration:      session.Duration,
+			SessionStatus: session.Status,
+		}
+		if record, exists := recordMap[session.ID]; exists {
+			checkinTime := record.CheckinTime
+			item.Status = record.Status
+			item.CheckinTime = &checkinTime
+		} else if session.Status == "active" {
+			item.Status = "pending"
+		} else {
+			item.Status = "absent"
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// GetCourseSummaries 获取学生所选每门课程的考勤汇总，只统计已结束的签到会话
+func (s *StudentService) GetCourseSummaries(studentID uint) ([]StudentCourseSummary, error) {
+	var enrollments []model.Enrollment
+	if err := database.DB.Preload("Course").Preload("Course.Teacher").Where("student_id = ?", studentID).Find(&enrollments).Error; err != nil {
+		return nil, err
+	}
+
+	history, err := s.GetAttendanceHistory(studentID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryMap := make(map[uint]*StudentCourseSummary, len(enrollments))
+	result := make([]StudentCourseSummary, len(enrollments))
+	for i, enrollment := range enrollments {
+		result[i] = StudentCourseSummary{
+			CourseID:    enrollment.CourseID,
+			CourseCode:  enrollment.Course.CourseCode,
+			CourseName:  enrollment.Course.Name,
+			TeacherName: enrollment.Course.Teacher.Name,
+			Semester:    enrollment.Course.Semester,
+		}
+		summaryMap[enrollment.CourseID] = &result[i]
+	}
+
+	for _, item := range history {
+		summary, exists := summaryMap[item.CourseID]
+		if !exists || item.SessionStatus != "ended" {
+			continue
+		}
+		summary.TotalSessions++
+		switch item.Status {
+		case "present":
+			summary.Present++
+		case "late":
+			summary.Late++
+		case "excused":
+			summary.Excused++
+		default:
+			summary.Absent++
+		}
+	}
+
+	for i := range result {
+		result[i].AttendanceRate = attendanceRate(result[i].Present, result[i].Late, result[i].Excused, result[i].TotalSessions)
+	}
+
+	return result, nil
+}
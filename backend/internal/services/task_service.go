@@ -4,17 +4,31 @@ import (
 	model "backend/internal/model"
 	"backend/pkg/database"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 // 定时任务服务
-type TaskService struct{}
+type TaskService struct {
+	warningService   *WarningService
+	detectionService *DetectionService
+	digestService    *DigestService
+	oidcService      *OIDCService
+	throttleService  *LoginThrottleService
+	digestRunning    atomic.Bool // 周报是否正在发送，避免发送耗时超过调度间隔时重复发送
+}
 
 // NewTaskService 创建新的定时任务服务实例
 func NewTaskService() *TaskService {
-	return &TaskService{}
+	return &TaskService{
+		warningService:   &WarningService{},
+		detectionService: &DetectionService{},
+		digestService:    &DigestService{},
+		oidcService:      &OIDCService{},
+		throttleService:  &LoginThrottleService{},
+	}
 }
 
 // AutoEndExpiredSessions 自动结束过期的签到会话
@@ -59,6 +73,80 @@ func (ts *TaskService) endSession(sessionID uint) error {
 	})
 }
 
+// ProcessEndedSessions 对已结束但尚未处理的签到会话执行异常签到检测，并对其所在课程评估考勤预警
+func (ts *TaskService) ProcessEndedSessions() {
+	var sessions []model.CheckinSession
+	if err := database.DB.Where("status = ? AND warning_evaluated = ?", "ended", false).Find(&sessions).Error; err != nil {
+		log.Printf("查询待处理签到会话失败: %v", err)
+		return
+	}
+
+	// 同一课程的多个会话只需评估一次预警
+	sessionIDsByCourse := make(map[uint][]uint)
+	for _, session := range sessions {
+		if err := ts.detectionService.EvaluateSession(session.ID); err != nil {
+			log.Printf("会话 %d 异常签到检测失败: %v", session.ID, err)
+		}
+		sessionIDsByCourse[session.CourseID] = append(sessionIDsByCourse[session.CourseID], session.ID)
+	}
+
+	for courseID, sessionIDs := range sessionIDsByCourse {
+		if err := ts.warningService.EvaluateCourse(courseID); err != nil {
+			log.Printf("评估课程 %d 考勤预警失败: %v", courseID, err)
+			continue
+		}
+		if err := database.DB.Model(&model.CheckinSession{}).Where("id IN ?", sessionIDs).Update("warning_evaluated", true).Error; err != nil {
+			log.Printf("更新会话处理状态失败: %v", err)
+		}
+	}
+}
+
+// SendWeeklyDigests 到达配置的周报发送时间时在后台向教师发送考勤周报
+// 逐封发送邮件可能较慢，不能阻塞其他定时任务
+func (ts *TaskService) SendWeeklyDigests() {
+	now := time.Now()
+	if !ts.digestService.WeeklyDigestDue(now) {
+		return
+	}
+	if !ts.digestRunning.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer ts.digestRunning.Store(false)
+		sent, err := ts.digestService.SendWeeklyDigests(now)
+		if err != nil {
+			log.Printf("发送考勤周报失败: %v", err)
+			return
+		}
+		log.Printf("已发送 %d 份考勤周报", sent)
+	}()
+}
+
+// CleanupExpiredOIDCLogins 清理过期的单点登录记录
+func (ts *TaskService) CleanupExpiredOIDCLogins() {
+	deleted, err := ts.oidcService.CleanupExpiredLogins()
+	if err != nil {
+		log.Printf("清理过期单点登录记录失败: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("已清理 %d 条过期单点登录记录", deleted)
+	}
+}
+
+// CleanupLoginThrottles 清理过期的登录限制记录
+func (ts *TaskService) CleanupLoginThrottles() {
+	deleted, err := ts.throttleService.CleanupExpired()
+	if err != nil {
+		log.Printf("清理过期登录限制记录失败: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("已清理 %d 条过期登录限制记录", deleted)
+	}
+}
+
 // StartTaskScheduler 启动定时任务调度器
 func (ts *TaskService) StartTaskScheduler() {
 	// 每分钟检查一次过期的签到会话
@@ -68,8 +156,12 @@ func (ts *TaskService) StartTaskScheduler() {
 			select {
 			case <-ticker.C:
 				ts.AutoEndExpiredSessions()
+				ts.ProcessEndedSessions()
+				ts.SendWeeklyDigests()
+				ts.CleanupExpiredOIDCLogins()
+				ts.CleanupLoginThrottles()
 			}
 		}
 	}()
-	log.Println("定时任务调度器已启动，每分钟检查过期签到会话")
+	log.Println("定时任务调度器已启动，每分钟检查过期签到会话、处理已结束会话、按时发送考勤周报并清理过期单点登录和登录限制记录")
 }
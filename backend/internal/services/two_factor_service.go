@@ -0,0 +1,313 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+// 双因素认证参数
+const (
+	twoFactorIssuer   = "考勤系统"
+	totpPeriod        = 30 // 验证码时间步（秒）
+	totpSkew          = 1  // 允许前后偏差的时间步数
+	recoveryCodeCount = 10
+
+	// TwoFactorChallengeTTL 登录第二步令牌的有效期
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// PurposeTwoFactorLogin 已启用双因素认证的用户输入验证码时使用的令牌用途
+	PurposeTwoFactorLogin = "2fa_login"
+	// PurposeTwoFactorSetup 角色强制要求但尚未启用双因素认证的用户完成绑定时使用的令牌用途
+	PurposeTwoFactorSetup = "2fa_setup"
+)
+
+var (
+	// ErrInvalidTwoFactorCode 验证码或恢复码错误
+	ErrInvalidTwoFactorCode = errors.New("验证码错误或已使用")
+	// ErrTwoFactorNotEnabled 用户未启用双因素认证
+	ErrTwoFactorNotEnabled = errors.New("未启用双因素认证")
+	// ErrTwoFactorAlreadyEnabled 用户已启用双因素认证
+	ErrTwoFactorAlreadyEnabled = errors.New("已启用双因素认证")
+)
+
+var totpOpts = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// TwoFactorService TOTP 双因素认证服务
+type TwoFactorService struct{}
+
+// TwoFactorSetup 绑定验证器应用所需的信息
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`  // Base32 密钥，供无法扫码时手动输入
+	URL    string `json:"url"`     // otpauth:// 地址
+	QRCode string `json:"qr_code"` // 二维码 PNG（Base64）
+}
+
+// IsEnabled 判断用户是否已启用双因素认证
+func (ts *TwoFactorService) IsEnabled(userID uint) (bool, error) {
+	var count int64
+	err := database.DB.Model(&model.UserTwoFactor{}).Where("user_id = ? AND enabled = ?", userID, true).Count(&count).Error
+	return count > 0, err
+}
+
+// IsRequired 判断角色是否被要求强制启用双因素认证
+func (ts *TwoFactorService) IsRequired(role string) (bool, error) {
+	var count int64
+	err := database.DB.Model(&model.TwoFactorPolicy{}).Where("role = ? AND required = ?", role, true).Count(&count).Error
+	return count > 0, err
+}
+
+// GetRequiredRoles 获取强制启用双因素认证的角色
+func (ts *TwoFactorService) GetRequiredRoles() ([]string, error) {
+	roles := []string{}
+	err := database.DB.Model(&model.TwoFactorPolicy{}).Where("required = ?", true).Order("role").Pluck("role", &roles).Error
+	return roles, err
+}
+
+// SetRequiredRoles 设置强制启用双因素认证的角色，未列出的角色不再强制
+// 新增强制角色中尚未启用双因素认证的用户令牌全部失效，重新登录时必须先完成绑定
+func (ts *TwoFactorService) SetRequiredRoles(roles []string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var previous []string
+		if err := tx.Model(&model.TwoFactorPolicy{}).Where("required = ?", true).Pluck("role", &previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&model.TwoFactorPolicy{}).Error; err != nil {
+			return err
+		}
+
+		wasRequired := make(map[string]bool, len(previous))
+		for _, role := range previous {
+			wasRequired[role] = true
+		}
+		added := make([]string, 0, len(roles))
+		for _, role := range roles {
+			if err := tx.Create(&model.TwoFactorPolicy{Role: role, Required: true}).Error; err != nil {
+				return err
+			}
+			if !wasRequired[role] {
+				added = append(added, role)
+			}
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		users := tx.Model(&model.User{}).Select("id").
+			Where("role IN ?", added).
+			Where("id NOT IN (?)", tx.Model(&model.UserTwoFactor{}).Select("user_id").Where("enabled = ?", true))
+		var userIDs []uint
+		if err := users.Pluck("id", &userIDs).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+		if err := tx.Model(&model.User{}).Where("id IN ?", userIDs).
+			UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.RefreshToken{}).
+			Where("user_id IN ? AND revoked_at IS NULL", userIDs).
+			Update("revoked_at", time.Now()).Error
+	})
+}
+
+// BeginSetup 生成新的 TOTP 密钥，验证通过前不会生效
+func (ts *TwoFactorService) BeginSetup(user *model.User) (*TwoFactorSetup, error) {
+	var record model.UserTwoFactor
+	err := database.DB.Where("user_id = ?", user.ID).First(&record).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && record.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      twoFactorIssuer,
+		AccountName: user.Username,
+		Period:      totpPeriod,
+		Digits:      totpOpts.Digits,
+		Algorithm:   totpOpts.Algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record.UserID = user.ID
+	record.Secret = key.Secret()
+	record.Enabled = false
+	record.LastUsedStep = 0
+	if err := database.DB.Save(&record).Error; err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(key.URL(), qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret: key.Secret(),
+		URL:    key.URL(),
+		QRCode: base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Enable 使用验证器生成的验证码确认绑定，启用后返回一组新的恢复码
+func (ts *TwoFactorService) Enable(userID uint, code string) ([]string, error) {
+	var record model.UserTwoFactor
+	if err := database.DB.Where("user_id = ?", userID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("请先生成双因素认证密钥")
+		}
+		return nil, err
+	}
+	if record.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if !ts.consumeTOTP(&record, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(&record).Updates(map[string]interface{}{
+		"enabled":    true,
+		"enabled_at": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return ts.RegenerateRecoveryCodes(userID)
+}
+
+// Disable 关闭双因素认证并删除恢复码，已签发的令牌随之失效
+func (ts *TwoFactorService) Disable(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return revokeUserTokens(tx, userID)
+	})
+}
+
+// Verify 校验验证码或恢复码，验证码和恢复码都只能使用一次
+func (ts *TwoFactorService) Verify(userID uint, code string) error {
+	var record model.UserTwoFactor
+	if err := database.DB.Where("user_id = ? AND enabled = ?", userID, true).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+
+	if ts.consumeTOTP(&record, code) || ts.consumeRecoveryCode(userID, code) {
+		return nil
+	}
+	return ErrInvalidTwoFactorCode
+}
+
+// RegenerateRecoveryCodes 生成新的恢复码，旧恢复码全部作废，明文只在此时返回一次
+func (ts *TwoFactorService) RegenerateRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		for i := 0; i < recoveryCodeCount; i++ {
+			code, err := generateRecoveryCode()
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&model.RecoveryCode{UserID: userID, CodeHash: hashToken(normalizeRecoveryCode(code))}).Error; err != nil {
+				return err
+			}
+			codes = append(codes, code)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RemainingRecoveryCodes 获取未使用的恢复码数量
+func (ts *TwoFactorService) RemainingRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&model.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// consumeTOTP 校验 TOTP 验证码，通过后记录时间步，同一时间步及更早的验证码不能再次使用
+func (ts *TwoFactorService) consumeTOTP(record *model.UserTwoFactor, code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != totpOpts.Digits.Length() {
+		return false
+	}
+
+	current := time.Now().Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= record.LastUsedStep {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(record.Secret, time.Unix(step*totpPeriod, 0), totpOpts)
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+
+		// 条件更新避免并发请求重复使用同一个验证码
+		result := database.DB.Model(&model.UserTwoFactor{}).
+			Where("id = ? AND last_used_step < ?", record.ID, step).
+			Update("last_used_step", step)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return false
+		}
+		record.LastUsedStep = step
+		return true
+	}
+	return false
+}
+
+// consumeRecoveryCode 校验并作废一个恢复码
+func (ts *TwoFactorService) consumeRecoveryCode(userID uint, code string) bool {
+	result := database.DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// generateRecoveryCode 生成形如 1a2b3-c4d5e 的恢复码
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := hex.EncodeToString(b)
+	return s[:5] + "-" + s[5:], nil
+}
+
+// normalizeRecoveryCode 忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
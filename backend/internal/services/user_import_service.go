@@ -0,0 +1,619 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"backend/pkg/utils"
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/mail"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// 批量导入参数
+const (
+	UserImportBatchSize = 500   // 每批写入的用户数
+	UserImportMaxRows   = 20000 // 单个文件最多导入的行数
+	userImportJobTTL    = 24 * time.Hour
+	minPasswordLength   = 6
+	initialPasswordLen  = 10
+)
+
+// 导入任务状态
+const (
+	UserImportValidated = "validated" // 预检完成，等待确认导入
+	UserImportRunning   = "running"   // 正在写入
+	UserImportCompleted = "completed" // 写入完成
+)
+
+// 导入行状态
+const (
+	importRowValid   = "valid"
+	importRowInvalid = "invalid"
+	importRowCreated = "created"
+	importRowFailed  = "failed"
+)
+
+var (
+	// ErrImportJobNotFound 导入任务不存在或已过期
+	ErrImportJobNotFound = errors.New("导入任务不存在或已过期")
+	// ErrImportJobStarted 导入任务已经开始，不能重复导入
+	ErrImportJobStarted = errors.New("该导入任务已经开始，不能重复导入")
+)
+
+// importColumns 表头名称到字段的映射，支持中英文表头
+var importColumns = map[string]string{
+	"username": "username", "用户名": "username", "学号": "username", "工号": "username", "学号/工号": "username",
+	"name": "name", "姓名": "name",
+	"role": "role", "角色": "role",
+	"email": "email", "邮箱": "email",
+	"password": "password", "密码": "password", "初始密码": "password",
+}
+
+// importRoles 可批量导入的角色，管理员只能逐个创建
+var importRoles = map[string]string{
+	"student": "student", "学生": "student",
+	"assistant": "assistant", "助教": "assistant",
+	"teacher": "teacher", "教师": "teacher", "老师": "teacher",
+}
+
+// UserImportRow 导入文件中的一行及其校验、写入结果
+type UserImportRow struct {
+	Row       int      `json:"row"` // 文件中的行号（含表头）
+	Username  string   `json:"username"`
+	Name      string   `json:"name"`
+	Role      string   `json:"role"`
+	Email     string   `json:"email"`
+	Status    string   `json:"status"`
+	Errors    []string `json:"errors,omitempty"`
+	UserID    uint     `json:"user_id,omitempty"`
+	password  string   // 初始密码，只保存在内存中
+	generated bool     // 初始密码是否由系统生成
+}
+
+// UserImportJob 一次批量导入：上传后预检，确认后在后台分批写入
+type UserImportJob struct {
+	mu         sync.Mutex
+	id         string
+	createdBy  uint
+	fileName   string
+	status     string
+	rows       []*UserImportRow
+	processed  int
+	created    int
+	failed     int
+	createdAt  time.Time
+	finishedAt *time.Time
+}
+
+// UserImportStatus 导入任务的状态快照
+type UserImportStatus struct {
+	ID         string           `json:"id"`
+	FileName   string           `json:"file_name"`
+	Status     string           `json:"status"`
+	Total      int              `json:"total"`
+	Valid      int              `json:"valid"`
+	Invalid    int              `json:"invalid"`
+	Processed  int              `json:"processed"` // 已处理的有效行数
+	Created    int              `json:"created"`
+	Failed     int              `json:"failed"`
+	Generated  int              `json:"generated_passwords"` // 由系统生成初始密码的行数
+	CreatedAt  time.Time        `json:"created_at"`
+	FinishedAt *time.Time       `json:"finished_at"`
+	Errors     []*UserImportRow `json:"errors"` // 预检不通过或写入失败的行
+}
+
+// UserImportService 批量导入用户，导入任务保存在内存中，超过 24 小时自动清理
+type UserImportService struct {
+	mu   sync.Mutex
+	jobs map[string]*UserImportJob
+}
+
+// ParseUserImportFile 读取 CSV 或 XLSX 文件，第一行为表头
+func ParseUserImportFile(fileName string, r io.Reader) ([]*UserImportRow, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var err error
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("CSV 文件格式错误: %w", err)
+		}
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("XLSX 文件格式错误: %w", err)
+		}
+		defer f.Close()
+		if records, err = f.GetRows(f.GetSheetName(0)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("仅支持 .csv 和 .xlsx 文件")
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("文件为空")
+	}
+
+	// 按表头确定各列位置
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		header = strings.TrimSpace(strings.TrimPrefix(header, "\ufeff"))
+		if field, ok := importColumns[strings.ToLower(header)]; ok {
+			columns[field] = i
+		}
+	}
+	for _, required := range []string{"username", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("缺少必需的列: %s", required)
+		}
+	}
+
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []*UserImportRow
+	for i, record := range records[1:] {
+		row := &UserImportRow{
+			Row:      i + 2,
+			Username: cell(record, "username"),
+			Name:     cell(record, "name"),
+			Role:     cell(record, "role"),
+			Email:    cell(record, "email"),
+			password: cell(record, "password"),
+		}
+		// 跳过空行
+		if row.Username == "" && row.Name == "" && row.Role == "" && row.Email == "" && row.password == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("文件中没有数据行")
+	}
+	if len(rows) > UserImportMaxRows {
+		return nil, fmt.Errorf("单个文件最多导入 %d 行，当前 %d 行", UserImportMaxRows, len(rows))
+	}
+	return rows, nil
+}
+
+// Validate 预检：校验每一行并检查用户名是否与文件内其他行或已有用户重复，不写入数据库
+// 用户名比较不区分大小写，与 MySQL 默认排序规则下唯一索引的判定一致
+// 未填写初始密码的行由系统生成随机密码；generateAll 为 true 时忽略文件中的密码，全部随机生成
+func (s *UserImportService) Validate(createdBy uint, fileName string, rows []*UserImportRow, generateAll bool) (*UserImportStatus, error) {
+	firstRow := make(map[string]int)
+	usernames := make([]string, 0, len(rows))
+	for _, row := range rows {
+		row.Errors = nil
+
+		if row.Username == "" {
+			row.Errors = append(row.Errors, "用户名不能为空")
+		} else if len(row.Username) > 191 {
+			row.Errors = append(row.Errors, "用户名过长")
+		} else if first, ok := firstRow[strings.ToLower(row.Username)]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("用户名与第 %d 行重复", first))
+		} else {
+			firstRow[strings.ToLower(row.Username)] = row.Row
+			usernames = append(usernames, strings.ToLower(row.Username))
+		}
+
+		if row.Name == "" {
+			row.Errors = append(row.Errors, "姓名不能为空")
+		}
+
+		if row.Role == "" {
+			row.Role = "student"
+		} else if role, ok := importRoles[strings.ToLower(row.Role)]; ok {
+			row.Role = role
+		} else {
+			row.Errors = append(row.Errors, "角色无效: "+row.Role+"（可选 student、assistant、teacher）")
+		}
+
+		if row.Email != "" {
+			if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+				row.Errors = append(row.Errors, "邮箱格式错误: "+row.Email)
+			}
+		}
+
+		if generateAll {
+			row.password = ""
+		}
+		if row.password == "" {
+			password, err := GenerateInitialPassword()
+			if err != nil {
+				return nil, err
+			}
+			row.password, row.generated = password, true
+		} else if len(row.password) < minPasswordLength {
+			row.Errors = append(row.Errors, fmt.Sprintf("初始密码至少 %d 位", minPasswordLength))
+		}
+	}
+
+	// 已删除的用户仍占用用户名的唯一索引，一并检查
+	existing := make(map[string]bool)
+	for start := 0; start < len(usernames); start += 1000 {
+		end := start + 1000
+		if end > len(usernames) {
+			end = len(usernames)
+		}
+		var found []string
+		if err := database.DB.Unscoped().Model(&model.User{}).
+			Where("LOWER(username) IN ?", usernames[start:end]).
+			Pluck("username", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, username := range found {
+			existing[strings.ToLower(username)] = true
+		}
+	}
+
+	for _, row := range rows {
+		if key := strings.ToLower(row.Username); existing[key] && firstRow[key] == row.Row {
+			row.Errors = append(row.Errors, "用户名已存在")
+		}
+		if len(row.Errors) > 0 {
+			row.Status = importRowInvalid
+		} else {
+			row.Status = importRowValid
+		}
+	}
+
+	id, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	job := &UserImportJob{
+		id:        id[:16],
+		createdBy: createdBy,
+		fileName:  fileName,
+		status:    UserImportValidated,
+		rows:      rows,
+		createdAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	s.cleanupLocked()
+	s.jobs[job.id] = job
+	s.mu.Unlock()
+
+	return job.snapshot(), nil
+}
+
+// Commit 确认导入，在后台分批写入预检通过的行
+func (s *UserImportService) Commit(jobID string, createdBy uint) (*UserImportStatus, error) {
+	job, err := s.getJob(jobID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	job.mu.Lock()
+	if job.status != UserImportValidated {
+		job.mu.Unlock()
+		return nil, ErrImportJobStarted
+	}
+	job.status = UserImportRunning
+	job.mu.Unlock()
+
+	go job.run()
+	return job.snapshot(), nil
+}
+
+// GetStatus 获取导入任务的状态和进度
+func (s *UserImportService) GetStatus(jobID string, createdBy uint) (*UserImportStatus, error) {
+	job, err := s.getJob(jobID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	return job.snapshot(), nil
+}
+
+// ResultRows 获取导入结果，导入完成后包含系统生成的初始密码
+// 下载可能中断，任务过期清理前允许重复下载
+func (s *UserImportService) ResultRows(jobID string, createdBy uint) ([]UserImportResultRow, error) {
+	job, err := s.getJob(jobID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	includePasswords := job.status == UserImportCompleted
+	result := make([]UserImportResultRow, 0, len(job.rows))
+	for _, row := range job.rows {
+		item := UserImportResultRow{
+			Row:      row.Row,
+			Username: row.Username,
+			Name:     row.Name,
+			Role:     row.Role,
+			Email:    row.Email,
+			Status:   importRowLabels[row.Status],
+			Message:  strings.Join(row.Errors, "；"),
+		}
+		if row.generated && row.Status == importRowCreated && includePasswords {
+			item.Password = row.password
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// UserImportResultRow 导入结果文件中的一行
+type UserImportResultRow struct {
+	Row      int
+	Username string
+	Name     string
+	Role     string
+	Email    string
+	Password string // 系统生成的初始密码
+	Status   string
+	Message  string
+}
+
+// UserImportResultHeader 导入结果文件的表头
+var UserImportResultHeader = []string{"行号", "用户名", "姓名", "角色", "邮箱", "初始密码", "结果", "说明"}
+
+// Values 按表头顺序返回各列的值，文本列经过公式注入处理
+func (r UserImportResultRow) Values() []string {
+	values := []string{r.Username, r.Name, r.Role, r.Email, r.Password, r.Status, r.Message}
+	for i, value := range values {
+		values[i] = SanitizeCell(value)
+	}
+	return append([]string{fmt.Sprint(r.Row)}, values...)
+}
+
+var importRowLabels = map[string]string{
+	importRowValid:   "预检通过",
+	importRowInvalid: "预检不通过",
+	importRowCreated: "已创建",
+	importRowFailed:  "创建失败",
+}
+
+// getJob 查找导入任务，只有发起导入的管理员可以访问
+func (s *UserImportService) getJob(jobID string, createdBy uint) (*UserImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanupLocked()
+	job, ok := s.jobs[jobID]
+	if !ok || job.createdBy != createdBy {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// cleanupLocked 清理过期的导入任务，调用方需持有 s.mu
+func (s *UserImportService) cleanupLocked() {
+	if s.jobs == nil {
+		s.jobs = make(map[string]*UserImportJob)
+	}
+	for id, job := range s.jobs {
+		job.mu.Lock()
+		expired := job.status != UserImportRunning && time.Since(job.createdAt) > userImportJobTTL
+		job.mu.Unlock()
+		if expired {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// run 分批写入预检通过的行，每批在一个事务中写入；整批失败时逐行写入以定位出错的行
+func (job *UserImportJob) run() {
+	var valid []*UserImportRow
+	for _, row := range job.rows {
+		if row.Status == importRowValid {
+			valid = append(valid, row)
+		}
+	}
+
+	for start := 0; start < len(valid); start += UserImportBatchSize {
+		end := start + UserImportBatchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		job.importBatch(valid[start:end])
+	}
+
+	now := time.Now()
+	job.mu.Lock()
+	job.status = UserImportCompleted
+	job.finishedAt = &now
+	log.Printf("批量导入 %s 完成：创建 %d 个用户，失败 %d 个", job.fileName, job.created, job.failed)
+	job.mu.Unlock()
+}
+
+// importBatch 并行计算密码哈希后写入一批用户
+func (job *UserImportJob) importBatch(rows []*UserImportRow) {
+	users := make([]model.User, len(rows))
+	hashErrs := make([]error, len(rows))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.NumCPU())
+	for i, row := range rows {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, row *UserImportRow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			hash, err := utils.HashPassword(row.password)
+			hashErrs[i] = err
+			users[i] = model.User{
+				Username:           row.Username,
+				Name:               row.Name,
+				PasswordHash:       hash,
+				Role:               row.Role,
+				Email:              row.Email,
+				MustChangePassword: true,
+			}
+			job.mu.Lock()
+			job.processed++
+			job.mu.Unlock()
+		}(i, row)
+	}
+	wg.Wait()
+
+	for _, err := range hashErrs {
+		if err != nil {
+			job.importRows(rows, users, hashErrs)
+			return
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&users, len(users)).Error
+	})
+	if err != nil {
+		log.Printf("批量导入整批写入失败，改为逐行写入: %v", err)
+		job.importRows(rows, users, hashErrs)
+		return
+	}
+
+	job.mu.Lock()
+	for i, row := range rows {
+		row.Status = importRowCreated
+		row.UserID = users[i].ID
+	}
+	job.created += len(rows)
+	job.mu.Unlock()
+}
+
+// importRows 逐行写入，记录每一行的结果
+func (job *UserImportJob) importRows(rows []*UserImportRow, users []model.User, hashErrs []error) {
+	for i, row := range rows {
+		err := hashErrs[i]
+		if err == nil {
+			users[i].ID = 0
+			err = database.DB.Create(&users[i]).Error
+		}
+
+		job.mu.Lock()
+		if err != nil {
+			row.Status = importRowFailed
+			row.Errors = append(row.Errors, "写入失败: "+err.Error())
+			job.failed++
+		} else {
+			row.Status = importRowCreated
+			row.UserID = users[i].ID
+			job.created++
+		}
+		job.mu.Unlock()
+	}
+}
+
+// snapshot 获取任务状态快照
+func (job *UserImportJob) snapshot() *UserImportStatus {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	status := &UserImportStatus{
+		ID:         job.id,
+		FileName:   job.fileName,
+		Status:     job.status,
+		Total:      len(job.rows),
+		Processed:  job.processed,
+		Created:    job.created,
+		Failed:     job.failed,
+		CreatedAt:  job.createdAt,
+		FinishedAt: job.finishedAt,
+		Errors:     []*UserImportRow{},
+	}
+	for _, row := range job.rows {
+		switch row.Status {
+		case importRowInvalid:
+			status.Invalid++
+		default:
+			status.Valid++
+		}
+		if row.generated && row.Status != importRowInvalid {
+			status.Generated++
+		}
+		if row.Status == importRowInvalid || row.Status == importRowFailed {
+			rowCopy := *row
+			rowCopy.Errors = append([]string(nil), row.Errors...)
+			status.Errors = append(status.Errors, &rowCopy)
+		}
+	}
+	return status
+}
+
+// passwordAlphabet 初始密码字符集，去掉了容易混淆的 0/O、1/l/I
+const passwordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GenerateInitialPassword 生成随机初始密码
+func GenerateInitialPassword() (string, error) {
+	b := make([]byte, initialPasswordLen)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// WriteUserImportResultXLSX 将导入结果写为 xlsx 工作簿
+func WriteUserImportResultXLSX(w io.Writer, rows []UserImportResultRow) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "导入结果"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, len(UserImportResultHeader))
+	for i, title := range UserImportResultHeader {
+		header[i] = title
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		values := row.Values()
+		cells := make([]interface{}, len(values))
+		for j, value := range values {
+			cells[j] = value
+		}
+		cells[0] = row.Row
+		axis, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(axis, cells); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
@@ -11,18 +11,58 @@ import (
 
 type UserService struct{}
 
-// 获取所有用户
-func (s *UserService) GetAllUsers() ([]model.User, error) {
+// UserListSpec 用户列表支持的排序和筛选参数
+var UserListSpec = ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":         "id",
+		"username":   "username",
+		"name":       "name",
+		"role":       "role",
+		"created_at": "created_at",
+	},
+	Filters: map[string]string{
+		"role":        "role = ?",
+		"auth_source": "auth_source = ?",
+	},
+	KeywordColumns: []string{"username", "name", "email"},
+}
+
+// StudentListSpec 学生列表支持的排序和筛选参数，course_id 筛选选修该课程的学生
+var StudentListSpec = ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":         "id",
+		"username":   "username",
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	Filters: map[string]string{
+		"course_id": "id IN (SELECT student_id FROM enrollments WHERE course_id = ? AND deleted_at IS NULL)",
+	},
+	KeywordColumns: []string{"username", "name"},
+}
+
+// 按条件查询用户，分页时同时返回总数
+func (s *UserService) ListUsers(q *ListQuery) ([]model.User, int64, error) {
+	query, total, err := q.Apply(database.DB.Model(&model.User{}))
+	if err != nil {
+		return nil, 0, err
+	}
 	var users []model.User
-	err := database.DB.Find(&users).Error
-	return users, err
+	err = query.Find(&users).Error
+	return users, total, err
 }
 
-// 获取所有学生
-func (s *UserService) GetStudents() ([]model.User, error) {
+// 按条件查询学生，分页时同时返回总数
+func (s *UserService) ListStudents(q *ListQuery) ([]model.User, int64, error) {
+	query, total, err := q.Apply(database.DB.Model(&model.User{}).Where("role = ?", "student"))
+	if err != nil {
+		return nil, 0, err
+	}
 	var students []model.User
-	err := database.DB.Where("role = ?", "student").Find(&students).Error
-	return students, err
+	err = query.Find(&students).Error
+	return students, total, err
 }
 
 // 获取所有教师
@@ -59,12 +99,14 @@ func (s *UserService) CreateUser(username, name, password, role, email string) (
 		return nil, errors.New("密码加密失败")
 	}
 
+	// 管理员设置的初始密码，用户首次登录时必须修改
 	user := model.User{
-		Username:     username,
-		Name:         name,
-		PasswordHash: hashedPassword,
-		Role:         role,
-		Email:        email,
+		Username:           username,
+		Name:               name,
+		PasswordHash:       hashedPassword,
+		Role:               role,
+		Email:              email,
+		MustChangePassword: true,
 	}
 
 	if err := database.DB.Create(&user).Error; err != nil {
@@ -83,11 +125,22 @@ func (s *UserService) UpdateUser(id uint, name, role, email string) (*model.User
 		return nil, err
 	}
 
+	roleChanged := user.Role != role
 	user.Name = name
 	user.Role = role
 	user.Email = email
 
-	if err := database.DB.Save(&user).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		// 角色变更后旧令牌中的角色已失效，需要重新登录
+		if roleChanged {
+			return revokeUserTokens(tx, user.ID)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -108,21 +161,46 @@ func (s *UserService) DeleteUser(id uint) error {
 		return errors.New("不允许删除管理员用户")
 	}
 
-	return database.DB.Delete(&user).Error
+	// 删除用户的同时吊销其全部令牌
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return revokeUserTokens(tx, user.ID)
+	})
 }
 
-// 更新用户密码
-func (s *UserService) UpdatePassword(id uint, newPassword string) error {
-	user, err := s.GetUserByID(id)
-	if err != nil {
+// 更新用户密码，统一身份认证账号的密码由目录管理
+// mustChange 为 true 时（管理员重置）用户下次登录时必须修改密码
+func (s *UserService) UpdatePassword(id uint, newPassword string, mustChange bool) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return updatePassword(tx, id, newPassword, mustChange)
+	})
+}
+
+// updatePassword 在给定事务中更新密码并吊销已签发的全部令牌
+func updatePassword(tx *gorm.DB, id uint, newPassword string, mustChange bool) error {
+	var user model.User
+	if err := tx.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
 		return err
 	}
+	if user.AuthSource != AuthSourceLocal {
+		return ErrExternalAccount
+	}
 
 	hashedPassword, err := utils.HashPassword(newPassword)
 	if err != nil {
 		return errors.New("密码加密失败")
 	}
 
-	user.PasswordHash = hashedPassword
-	return database.DB.Save(&user).Error
+	if err := tx.Model(&user).Updates(map[string]interface{}{
+		"password_hash":        hashedPassword,
+		"must_change_password": mustChange,
+	}).Error; err != nil {
+		return err
+	}
+	return revokeUserTokens(tx, user.ID)
 }
\ No newline at end of file
@@ -0,0 +1,272 @@
+package services
+
+import (
+	model "backend/internal/model"
+	"backend/pkg/database"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WarningService 考勤预警服务
+type WarningService struct {
+	reportService ReportService
+}
+
+// WarningRuleInput 创建或更新预警规则的参数
+type WarningRuleInput struct {
+	CourseID          *uint
+	Name              string
+	MaxAbsences       int
+	MinAttendanceRate float64
+	Severity          string
+	Enabled           bool
+}
+
+// validate 校验规则参数，至少需要启用一个阈值
+func (in WarningRuleInput) validate() error {
+	if in.MaxAbsences < 0 {
+		return errors.New("缺勤次数阈值不能为负数")
+	}
+	if in.MinAttendanceRate < 0 || in.MinAttendanceRate > 1 {
+		return errors.New("出勤率阈值应在 0 到 1 之间")
+	}
+	if in.MaxAbsences == 0 && in.MinAttendanceRate == 0 {
+		return errors.New("至少需要设置缺勤次数或出勤率阈值")
+	}
+	switch in.Severity {
+	case "info", "warning", "critical":
+	default:
+		return errors.New("无效的严重级别")
+	}
+	return nil
+}
+
+// GetRules 获取预警规则，courseID 不为空时返回该课程规则及系统级规则（调用方需已校验课程权限）
+// courseID 为空时管理员返回全部规则，教师和助教只返回系统级规则及其任课或被分配课程的规则
+func (ws *WarningService) GetRules(courseID *uint, userID uint, role string) ([]model.WarningRule, error) {
+	var rules []model.WarningRule
+	query := database.DB.Preload("Course")
+	switch {
+	case courseID != nil:
+		query = query.Where("course_id = ? OR course_id IS NULL", *courseID)
+	case role == "admin":
+	case role == "teacher":
+		query = query.Where("course_id IS NULL OR course_id IN (?)", database.DB.Model(&model.Course{}).Select("id").Where("teacher_id = ?", userID))
+	case role == "assistant":
+		query = query.Where("course_id IS NULL OR course_id IN (?)", database.DB.Model(&model.CourseAssistant{}).Select("course_id").Where("assistant_id = ?", userID))
+	default:
+		return rules, nil
+	}
+	err := query.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// GetRuleByID 根据ID获取预警规则
+func (ws *WarningService) GetRuleByID(id uint) (*model.WarningRule, error) {
+	var rule model.WarningRule
+	if err := database.DB.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("预警规则不存在")
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRule 创建预警规则
+func (ws *WarningService) CreateRule(in WarningRuleInput, createdBy uint) (*model.WarningRule, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+
+	rule := model.WarningRule{
+		CourseID:          in.CourseID,
+		Name:              in.Name,
+		MaxAbsences:       in.MaxAbsences,
+		MinAttendanceRate: in.MinAttendanceRate,
+		Severity:          in.Severity,
+		Enabled:           in.Enabled,
+		CreatedBy:         createdBy,
+	}
+	if err := database.DB.Create(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateRule 更新预警规则，规则所属课程不可修改
+// 停用规则时一并解除其触发的预警，EvaluateCourse 只评估启用的规则，不会再解除它们
+func (ws *WarningService) UpdateRule(id uint, in WarningRuleInput) (*model.WarningRule, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+
+	rule, err := ws.GetRuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Name = in.Name
+	rule.MaxAbsences = in.MaxAbsences
+	rule.MinAttendanceRate = in.MinAttendanceRate
+	rule.Severity = in.Severity
+	rule.Enabled = in.Enabled
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 显式指定字段，保证 false/0 值也会被更新
+		if err := tx.Model(rule).Select("name", "max_absences", "min_attendance_rate", "severity", "enabled").Updates(rule).Error; err != nil {
+			return err
+		}
+		if rule.Enabled {
+			return nil
+		}
+		return tx.Model(&model.AttendanceWarning{}).
+			Where("rule_id = ? AND status = ?", rule.ID, "active").
+			Updates(map[string]interface{}{"status": "resolved", "resolved_at": time.Now()}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除预警规则，并解除由其触发的预警
+func (ws *WarningService) DeleteRule(id uint) error {
+	rule, err := ws.GetRuleByID(id)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.AttendanceWarning{}).
+			Where("rule_id = ? AND status = ?", rule.ID, "active").
+			Updates(map[string]interface{}{"status": "resolved", "resolved_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(rule).Error
+	})
+}
+
+// EvaluateCourse 按课程规则和系统级规则评估课程内所有学生，生成或解除预警
+func (ws *WarningService) EvaluateCourse(courseID uint) error {
+	var rules []model.WarningRule
+	if err := database.DB.Where("enabled = ? AND (course_id = ? OR course_id IS NULL)", true, courseID).Find(&rules).Error; err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	summaries, err := ws.reportService.GetCourseStudentReport(courseID, DateRange{})
+	if err != nil {
+		return err
+	}
+
+	var activeWarnings []model.AttendanceWarning
+	if err := database.DB.Where("course_id = ? AND status = ?", courseID, "active").Find(&activeWarnings).Error; err != nil {
+		return err
+	}
+	activeMap := make(map[string]model.AttendanceWarning, len(activeWarnings))
+	for _, warning := range activeWarnings {
+		activeMap[fmt.Sprintf("%d-%d", warning.RuleID, warning.StudentID)] = warning
+	}
+
+	now := time.Now()
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, rule := range rules {
+			for _, summary := range summaries {
+				key := fmt.Sprintf("%d-%d", rule.ID, summary.StudentID)
+				existing, exists := activeMap[key]
+				reason := warningReason(rule, summary)
+
+				switch {
+				case reason != "" && exists:
+					// 预警仍然成立，更新最新统计
+					if err := tx.Model(&existing).Updates(map[string]interface{}{
+						"severity":        rule.Severity,
+						"reason":          reason,
+						"absent_count":    summary.Absent,
+						"attendance_rate": summary.AttendanceRate,
+					}).Error; err != nil {
+						return err
+					}
+				case reason != "":
+					warning := model.AttendanceWarning{
+						RuleID:         rule.ID,
+						CourseID:       courseID,
+						StudentID:      summary.StudentID,
+						Severity:       rule.Severity,
+						Reason:         reason,
+						AbsentCount:    summary.Absent,
+						AttendanceRate: summary.AttendanceRate,
+						Status:         "active",
+						TriggeredAt:    now,
+					}
+					if err := tx.Create(&warning).Error; err != nil {
+						return err
+					}
+				case exists:
+					// 不再满足触发条件（如补签或请假），解除预警
+					if err := tx.Model(&existing).Updates(map[string]interface{}{
+						"status":      "resolved",
+						"resolved_at": now,
+					}).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// warningReason 判断学生考勤是否触发规则，返回触发原因，未触发时返回空字符串
+func warningReason(rule model.WarningRule, summary StudentAttendanceSummary) string {
+	var reasons []string
+	if rule.MaxAbsences > 0 && summary.Absent >= rule.MaxAbsences {
+		reasons = append(reasons, fmt.Sprintf("缺勤 %d 次，达到阈值 %d 次", summary.Absent, rule.MaxAbsences))
+	}
+	if rule.MinAttendanceRate > 0 && summary.TotalSessions > summary.Excused && summary.AttendanceRate < rule.MinAttendanceRate {
+		reasons = append(reasons, fmt.Sprintf("出勤率 %.1f%%，低于阈值 %.1f%%", summary.AttendanceRate*100, rule.MinAttendanceRate*100))
+	}
+	return strings.Join(reasons, "；")
+}
+
+// WarningFilter 预警列表查询条件
+type WarningFilter struct {
+	CourseID  uint
+	StudentID uint
+	Status    string
+}
+
+// GetWarnings 按角色范围查询预警：教师为其课程，助教为被分配课程，学生仅本人，管理员不限制
+func (ws *WarningService) GetWarnings(userID uint, role string, filter WarningFilter) ([]model.AttendanceWarning, error) {
+	query := database.DB.Preload("Course").Preload("Student").Preload("Rule")
+
+	switch role {
+	case "teacher":
+		query = query.Where("course_id IN (?)", database.DB.Model(&model.Course{}).Select("id").Where("teacher_id = ?", userID))
+	case "assistant":
+		query = query.Where("course_id IN (?)", database.DB.Model(&model.CourseAssistant{}).Select("course_id").Where("assistant_id = ?", userID))
+	case "student":
+		query = query.Where("student_id = ?", userID)
+	}
+
+	if filter.CourseID != 0 {
+		query = query.Where("course_id = ?", filter.CourseID)
+	}
+	if filter.StudentID != 0 {
+		query = query.Where("student_id = ?", filter.StudentID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var warnings []model.AttendanceWarning
+	err := query.Order("triggered_at DESC").Find(&warnings).Error
+	return warnings, err
+}
@@ -0,0 +1,91 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// Event 日历中的一个事件
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Status      string // CONFIRMED, TENTATIVE, CANCELLED
+}
+
+// Calendar 一个 iCalendar（RFC 5545）日历
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Encode 将日历编码为 text/calendar 格式
+func (cal *Calendar) Encode(now time.Time) []byte {
+	var buf bytes.Buffer
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//Attendance System//Checkin Calendar//CN")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if cal.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(cal.Name))
+	}
+
+	stamp := formatTime(now)
+	for _, event := range cal.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+event.UID)
+		writeLine(&buf, "DTSTAMP:"+stamp)
+		writeLine(&buf, "DTSTART:"+formatTime(event.Start))
+		writeLine(&buf, "DTEND:"+formatTime(event.End))
+		writeLine(&buf, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Location != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(event.Location))
+		}
+		if event.Status != "" {
+			writeLine(&buf, "STATUS:"+event.Status)
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// formatTime 统一使用 UTC 时间，避免声明 VTIMEZONE
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText 按 RFC 5545 转义文本值中的特殊字符
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeLine 写入一行内容，超过 75 字节时按 RFC 5545 折行（不拆分 UTF-8 字符），行尾使用 CRLF
+func writeLine(buf *bytes.Buffer, line string) {
+	const limit = 75
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			buf.WriteString("\r\n ")
+			width = 1
+		}
+		buf.WriteRune(r)
+		width += size
+	}
+	buf.WriteString("\r\n")
+}
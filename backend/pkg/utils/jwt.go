@@ -8,25 +8,31 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// AccessTokenTTL 访问令牌有效期，过期后使用刷新令牌换取新的访问令牌
+const AccessTokenTTL = 15 * time.Minute
+
 var jwtKey []byte
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID       uint   `json:"user_id"`
+	Role         string `json:"role"`
+	TokenVersion uint   `json:"ver"`               // 与 User.TokenVersion 不一致时令牌已被吊销
+	Purpose      string `json:"purpose,omitempty"` // 专用令牌的用途，访问令牌为空
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, role string) (string, error) {
+func GenerateJWT(userID uint, role string, tokenVersion uint) (string, error) {
 	if jwtKey == nil {
 		jwtKey = []byte(config.Cfg.JWTSecret)
 		if jwtKey == nil {
 			return "", errors.New("JWT secret key is not configured")
 		}
 	}
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expirationTime := time.Now().Add(AccessTokenTTL)
 	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:       userID,
+		Role:         role,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
@@ -36,6 +42,35 @@ func GenerateJWT(userID uint, role string) (string, error) {
 	return token.SignedString(jwtKey)
 }
 
+// GeneratePurposeToken 生成只能用于特定用途的短期令牌（如登录第二步），不能作为访问令牌使用
+func GeneratePurposeToken(userID uint, purpose string, ttl time.Duration) (string, error) {
+	if jwtKey == nil {
+		jwtKey = []byte(config.Cfg.JWTSecret)
+	}
+	claims := &Claims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtKey)
+}
+
+// ValidatePurposeToken 校验专用令牌及其用途
+func ValidatePurposeToken(tokenStr, purpose string) (*Claims, error) {
+	claims, err := ValidateJWT(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, errors.New("无效的 JWT Token")
+	}
+	return claims, nil
+}
+
 func ValidateJWT(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"backend/internal/middleware"
+)
+
+// 角色组合
+var (
+	allRoles     = []string{"admin", "teacher", "assistant", "student"}
+	staffRoles   = []string{"admin", "teacher", "assistant"} // 可管理签到的角色
+	teacherRoles = []string{"admin", "teacher"}              // 可管理课程和规则的角色
+	adminRoles   = []string{"admin"}
+	studentRoles = []string{"student"}
+)
+
+// routePolicy 受保护路由的角色权限，新增受保护路由时必须在此声明，否则一律拒绝访问
+// 资源归属（如教师只能操作本人课程）仍由各接口自行校验
+var routePolicy = middleware.RoutePolicy{
+	// 签到
+	"POST /api/start-checkin":                 staffRoles,
+	"GET /api/checkin-sessions":               staffRoles,
+	"PUT /api/end-checkin/:session_id":        staffRoles,
+	"PUT /api/manual-end-checkin/:session_id": staffRoles,
+	"GET /api/records/:session_id":            staffRoles,
+	"GET /api/records/:session_id/export":     staffRoles,
+	"POST /api/manual-checkin/:session_id":    staffRoles,
+
+	// 课程
+	"GET /api/courses":                                 staffRoles,
+	"GET /api/courses/all":                             staffRoles,
+	"GET /api/courses/:id":                             staffRoles,
+	"POST /api/courses/add":                            teacherRoles,
+	"PUT /api/courses/:id":                             teacherRoles,
+	"DELETE /api/courses/:id":                          teacherRoles,
+	"GET /api/courses/:id/assistants":                  staffRoles,
+	"POST /api/courses/:id/assistants":                 teacherRoles,
+	"DELETE /api/courses/:id/assistants/:assistant_id": teacherRoles,
+
+	// 登录令牌
+	"POST /api/logout-all":      allRoles,
+	"POST /api/change-password": allRoles,
+
+	// 双因素认证
+	"GET /api/2fa":                 allRoles,
+	"POST /api/2fa/setup":          allRoles,
+	"POST /api/2fa/enable":         allRoles,
+	"POST /api/2fa/disable":        allRoles,
+	"POST /api/2fa/recovery-codes": allRoles,
+
+	// 日历订阅
+	"GET /api/calendar-token":        allRoles,
+	"POST /api/calendar-token/reset": allRoles,
+
+	// 看板与统计
+	"GET /api/dashboard":                                 staffRoles,
+	"GET /api/reports/courses/:course_id/students":       staffRoles,
+	"GET /api/reports/courses/:course_id/trend":          staffRoles,
+	"GET /api/reports/courses/:course_id/export":         staffRoles,
+	"GET /api/reports/courses/:course_id/arrivals":       staffRoles,
+	"GET /api/reports/sessions/:session_id/arrivals":     staffRoles,
+	"GET /api/reports/courses/:course_id/scores":         staffRoles,
+	"GET /api/reports/courses/:course_id/scoring-policy": staffRoles,
+	"PUT /api/reports/courses/:course_id/scoring-policy": teacherRoles,
+
+	// 考勤预警
+	"GET /api/warning-rules":        staffRoles,
+	"POST /api/warning-rules":       teacherRoles,
+	"PUT /api/warning-rules/:id":    teacherRoles,
+	"DELETE /api/warning-rules/:id": teacherRoles,
+	"GET /api/warnings":             allRoles,
+
+	// 异常签到复核、请假与申诉审核
+	"GET /api/checkin-flags":           staffRoles,
+	"PUT /api/checkin-flags/:id":       staffRoles,
+	"GET /api/attendance-requests":     staffRoles,
+	"PUT /api/attendance-requests/:id": staffRoles,
+
+	// 学生自助
+	"GET /api/me/courses":    studentRoles,
+	"GET /api/me/attendance": studentRoles,
+	"GET /api/me/requests":   studentRoles,
+	"POST /api/me/requests":  studentRoles,
+
+	// 选课与学生
+	"GET /api/enrollments":        staffRoles,
+	"POST /api/enrollments":       teacherRoles,
+	"DELETE /api/enrollments/:id": teacherRoles,
+	"GET /api/students":           staffRoles,
+
+	// 用户管理
+	"GET /api/users":              adminRoles,
+	"POST /api/users":             adminRoles,
+	"GET /api/users/:id":          adminRoles,
+	"PUT /api/users/:id":          adminRoles,
+	"DELETE /api/users/:id":       adminRoles,
+	"PUT /api/users/:id/password": adminRoles,
+	"DELETE /api/users/:id/2fa":   adminRoles,
+
+	"POST /api/user-imports":            adminRoles,
+	"GET /api/user-imports/:id":         adminRoles,
+	"POST /api/user-imports/:id/commit": adminRoles,
+	"GET /api/user-imports/:id/result":  adminRoles,
+
+	// 管理员
+	"GET /api/admin/analytics":       adminRoles,
+	"POST /api/admin/digests/send":   adminRoles,
+	"GET /api/admin/email-logs":      adminRoles,
+	"GET /api/admin/lockouts":        adminRoles,
+	"DELETE /api/admin/lockouts/:id": adminRoles,
+	"GET /api/admin/2fa-policy":      adminRoles,
+	"PUT /api/admin/2fa-policy":      adminRoles,
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"backend/config"
+	"backend/internal/handlers"
+	"backend/internal/middleware"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupRouter 注册所有路由，受保护路由的角色权限见 routePolicy
+func setupRouter() *gin.Engine {
+	r := gin.Default()
+
+	// 只有来自可信代理的请求才采用 X-Forwarded-For，避免客户端伪造来源 IP 绕过或触发登录限制
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("TRUSTED_PROXIES 配置无效: %v", err)
+	}
+
+	// 跨域配置
+	r.Use(func(c *gin.Context) {
+		// 支持多个来源
+		origin := c.Request.Header.Get("Origin")
+		allowedOrigins := map[string]bool{
+			"http://localhost:3000": true,
+			"http://localhost:5500": true,
+			"http://127.0.0.1:3000": true,
+		}
+
+		if allowedOrigins[origin] {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		// 列表接口通过 X-Total-Count 返回总数，跨域时需显式暴露给前端
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Total-Count")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	})
+
+	// 静态文件服务：H5签到页面
+	r.StaticFile("/checkin", "./static/checkin.html")
+
+	// API 路由组
+	api := r.Group("/api")
+	{
+		// 公共路由（无需登录）
+		api.POST("/login", handlers.Login)
+		api.POST("/login/2fa", handlers.LoginTwoFactor)
+		api.POST("/login/2fa/setup", handlers.LoginTwoFactorSetup)
+		api.POST("/login/2fa/enable", handlers.LoginTwoFactorEnable)
+		api.POST("/login/change-password", handlers.LoginChangePassword)
+		api.GET("/oidc/config", handlers.GetOIDCConfig)
+		api.GET("/oidc/login", handlers.OIDCLogin)
+		api.GET("/oidc/callback", handlers.OIDCCallback)
+		api.POST("/oidc/exchange", handlers.OIDCExchange)
+		api.POST("/refresh", handlers.RefreshToken)
+		api.POST("/logout", handlers.Logout)
+		api.POST("/forgot-password", handlers.ForgotPassword)
+		api.POST("/reset-password", handlers.ResetPassword)
+		api.GET("/session/:code", handlers.GetSessionInfo)
+		api.POST("/checkin", handlers.StudentCheckin)
+		api.GET("/teachers", handlers.GetTeachers)            // 将获取教师列表移到公共路由
+		api.GET("/calendar/:token", handlers.GetCalendarFeed) // 凭令牌订阅的 .ics 日历
+
+		// 受保护路由（需JWT认证）
+		protected := api.Use(middleware.JWTAuth(), middleware.Authorize(routePolicy))
+		{
+			// 签到相关接口
+			protected.POST("/start-checkin", handlers.StartCheckin)
+			protected.GET("/courses", handlers.GetMyCourses)   // 获取当前教师的课程
+			protected.GET("/courses/all", handlers.GetCourses) // 获取所有课程
+			protected.GET("/courses/:id", handlers.GetCourseByID)
+			protected.POST("/courses/add", handlers.CreateCourse)
+			protected.PUT("/courses/:id", handlers.UpdateCourse)    // 添加更新课程路由
+			protected.DELETE("/courses/:id", handlers.DeleteCourse) // 添加删除课程路由
+			protected.GET("/courses/:id/assistants", handlers.GetCourseAssistants)
+			protected.POST("/courses/:id/assistants", handlers.AddCourseAssistant)
+			protected.DELETE("/courses/:id/assistants/:assistant_id", handlers.RemoveCourseAssistant)
+			protected.GET("/records/:session_id", handlers.GetCheckinRecords)
+			protected.GET("/records/:session_id/export", handlers.ExportCheckinRecords)
+			protected.POST("/manual-checkin/:session_id", handlers.ManualCheckin) // 添加补签接口
+			protected.GET("/checkin-sessions", handlers.GetCheckinSessions)
+			protected.PUT("/end-checkin/:session_id", handlers.EndCheckinSession)
+			protected.PUT("/manual-end-checkin/:session_id", handlers.ManualEndCheckinSession)
+
+			// 退出所有设备、修改密码
+			protected.POST("/logout-all", handlers.LogoutAll)
+			protected.POST("/change-password", handlers.ChangePassword)
+
+			// 双因素认证
+			protected.GET("/2fa", handlers.GetTwoFactorStatus)
+			protected.POST("/2fa/setup", handlers.SetupTwoFactor)
+			protected.POST("/2fa/enable", handlers.EnableTwoFactor)
+			protected.POST("/2fa/disable", handlers.DisableTwoFactor)
+			protected.POST("/2fa/recovery-codes", handlers.RegenerateRecoveryCodes)
+
+			// 日历订阅地址
+			protected.GET("/calendar-token", handlers.GetCalendarToken)
+			protected.POST("/calendar-token/reset", handlers.ResetCalendarToken)
+
+			// 教师首页看板
+			protected.GET("/dashboard", handlers.GetTeacherDashboard)
+
+			// 考勤统计接口
+			protected.GET("/reports/courses/:course_id/students", handlers.GetCourseStudentReport)
+			protected.GET("/reports/courses/:course_id/trend", handlers.GetCourseAttendanceTrend)
+			protected.GET("/reports/courses/:course_id/export", handlers.ExportCourseAttendanceMatrix)
+			protected.GET("/reports/courses/:course_id/arrivals", handlers.GetCourseArrivalDistribution)
+			protected.GET("/reports/sessions/:session_id/arrivals", handlers.GetSessionArrivalDistribution)
+			protected.GET("/reports/courses/:course_id/scores", handlers.GetCourseScores)
+			protected.GET("/reports/courses/:course_id/scoring-policy", handlers.GetScoringPolicy)
+			protected.PUT("/reports/courses/:course_id/scoring-policy", handlers.UpdateScoringPolicy)
+
+			// 考勤预警接口
+			protected.GET("/warning-rules", handlers.GetWarningRules)
+			protected.POST("/warning-rules", handlers.CreateWarningRule)
+			protected.PUT("/warning-rules/:id", handlers.UpdateWarningRule)
+			protected.DELETE("/warning-rules/:id", handlers.DeleteWarningRule)
+			protected.GET("/warnings", handlers.GetWarnings)
+
+			// 异常签到复核接口
+			protected.GET("/checkin-flags", handlers.GetCheckinFlags)
+			protected.PUT("/checkin-flags/:id", handlers.ReviewCheckinFlag)
+
+			// 请假与申诉审核接口
+			protected.GET("/attendance-requests", handlers.GetAttendanceRequests)
+			protected.PUT("/attendance-requests/:id", handlers.ReviewAttendanceRequest)
+
+			// 学生自助查询接口（仅学生本人）
+			studentOnly := api.Group("/me")
+			{
+				studentOnly.GET("/courses", handlers.GetMyEnrolledCourses)
+				studentOnly.GET("/attendance", handlers.GetMyAttendance)
+				studentOnly.GET("/requests", handlers.GetMyRequests)
+				studentOnly.POST("/requests", handlers.CreateMyRequest)
+			}
+
+			// 选课管理接口
+			protected.GET("/enrollments", handlers.GetEnrollments)
+			protected.POST("/enrollments", handlers.CreateEnrollment)
+			protected.DELETE("/enrollments/:id", handlers.DeleteEnrollment)
+
+			// 学生管理接口
+			protected.GET("/students", handlers.GetStudents)
+
+			// 用户管理接口（仅管理员）
+			adminOnly := api.Group("/")
+			{
+				adminOnly.GET("/users", handlers.GetUsers)
+				adminOnly.POST("/users", handlers.CreateUser)
+				adminOnly.GET("/users/:id", handlers.GetUser)
+				adminOnly.PUT("/users/:id", handlers.UpdateUser)
+				adminOnly.DELETE("/users/:id", handlers.DeleteUser)
+				adminOnly.PUT("/users/:id/password", handlers.ResetUserPassword)
+				adminOnly.DELETE("/users/:id/2fa", handlers.ResetUserTwoFactor)
+
+				// 批量导入用户：上传预检、确认导入、查询进度、下载结果
+				adminOnly.POST("/user-imports", handlers.ImportUsers)
+				adminOnly.GET("/user-imports/:id", handlers.GetUserImport)
+				adminOnly.POST("/user-imports/:id/commit", handlers.CommitUserImport)
+				adminOnly.GET("/user-imports/:id/result", handlers.DownloadUserImportResult)
+			}
+
+			// 管理员考勤分析接口
+			analytics := api.Group("/admin")
+			{
+				analytics.GET("/analytics", handlers.GetAttendanceAnalytics)
+				analytics.POST("/digests/send", handlers.SendWeeklyDigests)
+				analytics.GET("/email-logs", handlers.GetEmailLogs)
+				analytics.GET("/lockouts", handlers.GetLoginLockouts)
+				analytics.DELETE("/lockouts/:id", handlers.ClearLoginLockout)
+				analytics.GET("/2fa-policy", handlers.GetTwoFactorPolicy)
+				analytics.PUT("/2fa-policy", handlers.UpdateTwoFactorPolicy)
+			}
+		}
+	}
+
+	return r
+}
+
+// trustedProxies 解析配置中的可信代理列表，未配置时返回 nil，即不信任任何代理
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(config.Cfg.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
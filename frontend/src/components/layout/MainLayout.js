@@ -7,7 +7,9 @@ import {
   TeamOutlined,
   LogoutOutlined,
   DashboardOutlined,
-  SolutionOutlined
+  SolutionOutlined,
+  KeyOutlined,
+  SafetyOutlined
 } from '@ant-design/icons';
 import { useNavigate, useLocation, Outlet } from 'react-router-dom';
 import useAuth from '../../hooks/useAuth';
@@ -106,6 +108,22 @@ const MainLayout = () => {
           <span style={{ marginRight: '10px' }}>
             欢迎, {user?.name || user?.Name} ({getUserRoleLabel()})
           </span>
+          <Button 
+            type="link" 
+            onClick={() => navigate('/change-password')}
+            icon={<KeyOutlined />}
+            style={{ color: 'white' }}
+          >
+            修改密码
+          </Button>
+          <Button 
+            type="link" 
+            onClick={() => navigate('/two-factor')}
+            icon={<SafetyOutlined />}
+            style={{ color: 'white' }}
+          >
+            双因素认证
+          </Button>
           <Button 
             type="link" 
             onClick={handleLogout}
@@ -2,11 +2,13 @@
 export const ROLES = {
   ADMIN: 'admin',
   TEACHER: 'teacher',
+  ASSISTANT: 'assistant',
   STUDENT: 'student'
 };
 
 export const ROLE_LABELS = {
   [ROLES.ADMIN]: '管理员',
   [ROLES.TEACHER]: '教师',
+  [ROLES.ASSISTANT]: '助教',
   [ROLES.STUDENT]: '学生'
 };
\ No newline at end of file
@@ -25,8 +25,7 @@ const useAuth = () => {
 
   const logout = () => {
     setUser(null);
-    localStorage.removeItem('authUser');
-    localStorage.removeItem('authToken');
+    AuthService.logout();
   };
 
   // 监听 localStorage 变化，以便在多个标签页之间同步用户状态
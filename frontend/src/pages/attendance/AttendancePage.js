@@ -35,6 +35,9 @@ const AttendancePage = () => {
   const { user } = useAuth(); // 获取当前用户信息
   const [courses, setCourses] = useState([]);
   const [sessions, setSessions] = useState([]);
+  // 签到会话服务端分页，按开始时间倒序
+  const [sessionPagination, setSessionPagination] = useState({ current: 1, pageSize: 10, total: 0 });
+  const [sessionStatus, setSessionStatus] = useState(undefined);
   const [loading, setLoading] = useState(false);
   const [isStartModalVisible, setIsStartModalVisible] = useState(false);
   const [isDetailModalVisible, setIsDetailModalVisible] = useState(false);
@@ -65,13 +68,19 @@ const AttendancePage = () => {
   };
 
   // 获取签到会话列表
-  const fetchSessions = async () => {
+  const fetchSessions = async (page = sessionPagination.current, pageSize = sessionPagination.pageSize, status = sessionStatus) => {
     setLoading(true);
     try {
-      const response = await CheckinService.getCheckinSessions();
+      const response = await CheckinService.getCheckinSessions({
+        page,
+        page_size: pageSize,
+        sort: '-start_time',
+        status,
+      });
+      const result = response.data?.data || {};
       
       // 处理会话数据，确保课程名称正确显示
-      const sessionsWithCourseName = (response.data?.data || []).map(session => {
+      const sessionsWithCourseName = (result.items || []).map(session => {
         
         // 尝试从不同可能的字段中获取课程名称
         const courseName = session.courseName || 
@@ -87,6 +96,7 @@ const AttendancePage = () => {
       });
       
       setSessions(sessionsWithCourseName);
+      setSessionPagination({ current: page, pageSize, total: result.total || 0 });
     } catch (error) {
       message.error(error.message || '获取签到会话失败');
     } finally {
@@ -245,6 +255,12 @@ const AttendancePage = () => {
       title: '状态',
       dataIndex: 'status',
       key: 'status',
+      filters: [
+        { text: '进行中', value: 'active' },
+        { text: '已结束', value: 'ended' },
+      ],
+      filterMultiple: false,
+      filteredValue: sessionStatus ? [sessionStatus] : null,
       render: (status) => getStatusTag(status),
     },
     {
@@ -397,7 +413,7 @@ const AttendancePage = () => {
             extra={
               <Button 
                 icon={<ReloadOutlined />} 
-                onClick={fetchSessions}
+                onClick={() => fetchSessions()}
               >
                 刷新
               </Button>
@@ -408,7 +424,17 @@ const AttendancePage = () => {
               columns={sessionColumns} 
               rowKey="id" 
               loading={loading}
-              pagination={{ pageSize: 10 }}
+              pagination={{ ...sessionPagination, showTotal: (total) => `共 ${total} 条` }}
+              onChange={(pag, filters) => {
+                const status = filters.status?.[0];
+                // 筛选条件变化时回到第一页
+                if (status !== sessionStatus) {
+                  setSessionStatus(status);
+                  fetchSessions(1, pag.pageSize, status);
+                  return;
+                }
+                fetchSessions(pag.current, pag.pageSize, status);
+              }}
             />
           </Card>
         </Col>
@@ -0,0 +1,78 @@
+import React, { useState } from 'react';
+import { Form, Input, Button, Card, App } from 'antd';
+import { LockOutlined } from '@ant-design/icons';
+import AuthService from '../../services/authService';
+
+const ChangePasswordPage = () => {
+  const { message: messageApi } = App.useApp();
+  const [loading, setLoading] = useState(false);
+  const [form] = Form.useForm();
+
+  const onFinish = async (values) => {
+    setLoading(true);
+    try {
+      await AuthService.changePassword({
+        current_password: values.currentPassword,
+        new_password: values.newPassword,
+      });
+      messageApi.success('密码修改成功，其他设备需要重新登录');
+      form.resetFields();
+    } catch (error) {
+      messageApi.error(error.response?.data?.msg || '修改密码失败');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <Card title="修改密码" style={{ maxWidth: 480 }}>
+      <Form form={form} name="change-password" layout="vertical" onFinish={onFinish}>
+        <Form.Item
+          name="currentPassword"
+          label="当前密码"
+          rules={[{ required: true, message: '请输入当前密码!' }]}
+        >
+          <Input.Password prefix={<LockOutlined />} autoComplete="current-password" />
+        </Form.Item>
+
+        <Form.Item
+          name="newPassword"
+          label="新密码"
+          rules={[
+            { required: true, message: '请输入新密码!' },
+            { min: 6, message: '密码至少 6 位!' }
+          ]}
+        >
+          <Input.Password prefix={<LockOutlined />} autoComplete="new-password" />
+        </Form.Item>
+
+        <Form.Item
+          name="confirm"
+          label="确认新密码"
+          dependencies={['newPassword']}
+          rules={[
+            { required: true, message: '请再次输入新密码!' },
+            ({ getFieldValue }) => ({
+              validator(_, value) {
+                if (!value || getFieldValue('newPassword') === value) {
+                  return Promise.resolve();
+                }
+                return Promise.reject(new Error('两次输入的密码不一致!'));
+              },
+            }),
+          ]}
+        >
+          <Input.Password prefix={<LockOutlined />} autoComplete="new-password" />
+        </Form.Item>
+
+        <Form.Item>
+          <Button type="primary" htmlType="submit" loading={loading}>
+            修改密码
+          </Button>
+        </Form.Item>
+      </Form>
+    </Card>
+  );
+};
+
+export default ChangePasswordPage;
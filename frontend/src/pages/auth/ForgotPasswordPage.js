@@ -0,0 +1,57 @@
+import React, { useState } from 'react';
+import { Form, Input, Button, Card, App } from 'antd';
+import { UserOutlined } from '@ant-design/icons';
+import { Link } from 'react-router-dom';
+import AuthService from '../../services/authService';
+
+const ForgotPasswordPage = () => {
+  const { message: messageApi } = App.useApp();
+  const [loading, setLoading] = useState(false);
+  const [submitted, setSubmitted] = useState(false);
+
+  const onFinish = async (values) => {
+    setLoading(true);
+    try {
+      await AuthService.forgotPassword(values.username);
+      setSubmitted(true);
+    } catch (error) {
+      messageApi.error(error.response?.data?.msg || '提交失败，请稍后再试');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div style={{ 
+      display: 'flex', 
+      justifyContent: 'center', 
+      alignItems: 'center', 
+      height: '100vh', 
+      background: '#f0f2f5' 
+    }}>
+      <Card title="找回密码" style={{ width: 400 }}>
+        {submitted ? (
+          <p>如果账号存在且绑定了邮箱，重置链接已发送到该邮箱，请在 30 分钟内完成重置。</p>
+        ) : (
+          <Form name="forgot-password" onFinish={onFinish}>
+            <Form.Item
+              name="username"
+              rules={[{ required: true, message: '请输入用户名或邮箱!' }]}
+            >
+              <Input prefix={<UserOutlined />} placeholder="用户名或邮箱" />
+            </Form.Item>
+
+            <Form.Item>
+              <Button type="primary" htmlType="submit" loading={loading} block>
+                发送重置链接
+              </Button>
+            </Form.Item>
+          </Form>
+        )}
+        <Link to="/login">返回登录</Link>
+      </Card>
+    </div>
+  );
+};
+
+export default ForgotPasswordPage;
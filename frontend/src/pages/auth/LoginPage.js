@@ -1,7 +1,7 @@
-import React, { useState } from 'react';
-import { Form, Input, Button, Card, App } from 'antd';
-import { UserOutlined, LockOutlined } from '@ant-design/icons';
-import { useNavigate } from 'react-router-dom';
+import React, { useEffect, useRef, useState } from 'react';
+import { Form, Input, Button, Card, App, Modal, Typography, Divider } from 'antd';
+import { UserOutlined, LockOutlined, SafetyOutlined, LoginOutlined } from '@ant-design/icons';
+import { Link, useNavigate, useSearchParams } from 'react-router-dom';
 import AuthService from '../../services/authService';
 import { ROLES } from '../../constants/roles';
 
@@ -9,12 +9,132 @@ const LoginPage = () => {
   const { message: messageApi } = App.useApp();
   const [loading, setLoading] = useState(false);
   const navigate = useNavigate();
+  // 双因素认证：login 为输入验证码，setup 为角色强制要求时首次绑定
+  const [twoFactor, setTwoFactor] = useState(null);
+  const [setupInfo, setSetupInfo] = useState(null);
+  // 首次登录或管理员重置密码后必须先修改密码，保存改密专用令牌
+  const [passwordChangeToken, setPasswordChangeToken] = useState(null);
+  // 单点登录
+  const [sso, setSso] = useState(null);
+  const [searchParams, setSearchParams] = useSearchParams();
+  const ssoHandled = useRef(false);
+
+  useEffect(() => {
+    AuthService.getOIDCConfig()
+      .then((response) => setSso(response.data))
+      .catch(() => setSso(null));
+  }, []);
+
+  // 身份提供方登录完成后带一次性换取码跳回登录页
+  useEffect(() => {
+    const code = searchParams.get('sso_code');
+    const ssoError = searchParams.get('sso_error');
+    if (ssoHandled.current || (!code && !ssoError)) {
+      return;
+    }
+    ssoHandled.current = true;
+    setSearchParams({}, { replace: true });
+
+    if (ssoError) {
+      messageApi.error(ssoError);
+      return;
+    }
+    setLoading(true);
+    AuthService.exchangeOIDCCode(code)
+      .then(handleLoginResult)
+      .catch((error) => messageApi.error(error.response?.data?.msg || '单点登录失败'))
+      .finally(() => setLoading(false));
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [searchParams]);
+
+  // 处理登录结果：需要双因素认证或修改初始密码时进入下一步，否则保存令牌
+  const handleLoginResult = async (response) => {
+    const data = response.data || {};
+
+    if (data.must_change_password) {
+      setTwoFactor(null);
+      setSetupInfo(null);
+      setPasswordChangeToken(data.password_change_token);
+      return;
+    }
+
+    if (data.two_factor_required) {
+      setTwoFactor({ mode: 'login', challengeToken: data.challenge_token });
+      return;
+    }
+    if (data.two_factor_setup_required) {
+      const setup = await AuthService.loginTwoFactorSetup(data.challenge_token);
+      setSetupInfo(setup.data);
+      setTwoFactor({ mode: 'setup', challengeToken: data.challenge_token });
+      return;
+    }
+
+    completeLogin(response);
+  };
 
   const onFinish = async (values) => {
     setLoading(true);
     try {
       const response = await AuthService.login(values);
-      
+      await handleLoginResult(response);
+    } catch (error) {
+      showLoginError(error);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const onTwoFactorFinish = async ({ code }) => {
+    setLoading(true);
+    try {
+      if (twoFactor.mode === 'setup') {
+        const response = await AuthService.loginTwoFactorEnable(twoFactor.challengeToken, code);
+        Modal.info({
+          title: '请妥善保存恢复码',
+          content: (
+            <div>
+              <p>验证器不可用时，可使用恢复码登录，每个恢复码只能使用一次：</p>
+              <Typography.Paragraph copyable={{ text: response.data.recovery_codes.join('\n') }}>
+                {response.data.recovery_codes.map((item) => <div key={item}><code>{item}</code></div>)}
+              </Typography.Paragraph>
+            </div>
+          ),
+          onOk: () => handleLoginResult(response),
+        });
+        return;
+      }
+
+      const response = await AuthService.loginTwoFactor(twoFactor.challengeToken, code);
+      await handleLoginResult(response);
+    } catch (error) {
+      if (error.response?.status === 401 && error.response.data?.msg?.includes('过期')) {
+        setTwoFactor(null);
+        setSetupInfo(null);
+      }
+      messageApi.error(error.response?.data?.msg || '验证失败，请重试');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const onPasswordChangeFinish = async ({ newPassword }) => {
+    setLoading(true);
+    try {
+      const response = await AuthService.completeRequiredPasswordChange(passwordChangeToken, newPassword);
+      setPasswordChangeToken(null);
+      completeLogin(response);
+    } catch (error) {
+      if (error.response?.status === 401) {
+        setPasswordChangeToken(null);
+      }
+      messageApi.error(error.response?.data?.msg || '修改密码失败，请重试');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const completeLogin = (response) => {
+    try {
       // 正确处理后端返回的数据格式
       const userData = response.data?.user || response.data || response;
       const token = response.data?.token || response.token;
@@ -32,6 +152,10 @@ const LoginPage = () => {
         role: userData.role || userData.Role
       }));
       localStorage.setItem('authToken', token);
+      const refreshToken = response.data?.refresh_token || response.refresh_token;
+      if (refreshToken) {
+        localStorage.setItem('refreshToken', refreshToken);
+      }
       messageApi.success('登录成功');
       
       // 根据角色跳转到不同页面
@@ -44,62 +168,64 @@ const LoginPage = () => {
         navigate('/dashboard');
       }
     } catch (error) {
-      console.error('Login failed:', error);
-      
-      // 提供更具体和友好的错误提示
-      let errorMessage = '登录失败，请检查用户名和密码';
-      
-      // 根据不同的错误类型提供具体的错误信息
-      if (error.response) {
-        // 服务器返回了错误响应
-        switch (error.response.status) {
-          case 401:
-            errorMessage = '用户名或密码错误，请重新输入';
-            break;
-          case 403:
-            errorMessage = '账户已被禁用，请联系管理员';
-            break;
-          case 404:
-            errorMessage = '登录服务不可用，请稍后再试';
-            break;
-          case 500:
-            errorMessage = '服务器内部错误，请稍后再试';
-            break;
-          default:
-            // 尝试从响应数据中获取错误消息
-            if (error.response.data) {
-              if (typeof error.response.data === 'string') {
-                errorMessage = error.response.data;
-              } else if (error.response.data.msg) {
-                errorMessage = error.response.data.msg;
-              } else if (error.response.data.message) {
-                errorMessage = error.response.data.message;
-              } else if (error.response.data.error) {
-                errorMessage = error.response.data.error;
-              } else if (typeof error.response.data === 'object') {
-                // 尝试从嵌套对象中获取错误消息
-                const data = error.response.data;
-                if (data.data && typeof data.data === 'string') {
-                  errorMessage = data.data;
-                } else if (data.data && data.data.msg) {
-                  errorMessage = data.data.msg;
-                }
+      showLoginError(error);
+    }
+  };
+
+  const showLoginError = (error) => {
+    console.error('Login failed:', error);
+    
+    // 提供更具体和友好的错误提示
+    let errorMessage = '登录失败，请检查用户名和密码';
+    
+    // 根据不同的错误类型提供具体的错误信息
+    if (error.response) {
+      // 服务器返回了错误响应
+      switch (error.response.status) {
+        case 401:
+          errorMessage = '用户名或密码错误，请重新输入';
+          break;
+        case 403:
+          errorMessage = '账户已被禁用，请联系管理员';
+          break;
+        case 404:
+          errorMessage = '登录服务不可用，请稍后再试';
+          break;
+        case 500:
+          errorMessage = '服务器内部错误，请稍后再试';
+          break;
+        default:
+          // 尝试从响应数据中获取错误消息
+          if (error.response.data) {
+            if (typeof error.response.data === 'string') {
+              errorMessage = error.response.data;
+            } else if (error.response.data.msg) {
+              errorMessage = error.response.data.msg;
+            } else if (error.response.data.message) {
+              errorMessage = error.response.data.message;
+            } else if (error.response.data.error) {
+              errorMessage = error.response.data.error;
+            } else if (typeof error.response.data === 'object') {
+              // 尝试从嵌套对象中获取错误消息
+              const data = error.response.data;
+              if (data.data && typeof data.data === 'string') {
+                errorMessage = data.data;
+              } else if (data.data && data.data.msg) {
+                errorMessage = data.data.msg;
               }
             }
-        }
-      } else if (error.request) {
-        // 请求已发出但没有收到响应
-        errorMessage = '网络连接异常，请检查网络设置';
-      } else if (error.message) {
-        // 其他错误
-        errorMessage = error.message;
+          }
       }
-      
-      // 显示错误消息
-      messageApi.error(errorMessage);
-    } finally {
-      setLoading(false);
+    } else if (error.request) {
+      // 请求已发出但没有收到响应
+      errorMessage = '网络连接异常，请检查网络设置';
+    } else if (error.message) {
+      // 其他错误
+      errorMessage = error.message;
     }
+    
+    // 显示错误消息
+    messageApi.error(errorMessage);
   };
 
   return (
@@ -111,44 +237,132 @@ const LoginPage = () => {
       background: '#f0f2f5' 
     }}>
       <Card title="考勤系统登录" style={{ width: 400 }}>
-        <Form
-          name="login"
-          initialValues={{ remember: true }}
-          onFinish={onFinish}
-        >
-          <Form.Item
-            name="username"
-            rules={[{ required: true, message: '请输入用户名!' }]}
-          >
-            <Input 
-              prefix={<UserOutlined />} 
-              placeholder="用户名" 
-              autoComplete="username"
-            />
-          </Form.Item>
-
-          <Form.Item
-            name="password"
-            rules={[{ required: true, message: '请输入密码!' }]}
-          >
-            <Input.Password 
-              prefix={<LockOutlined />} 
-              placeholder="密码" 
-              autoComplete="current-password"
-            />
-          </Form.Item>
-
-          <Form.Item>
-            <Button 
-              type="primary" 
-              htmlType="submit" 
-              loading={loading} 
-              block
+        {passwordChangeToken ? (
+          <Form name="password-change" onFinish={onPasswordChangeFinish}>
+            <p>为了账号安全，首次登录或密码被重置后需要设置新密码。</p>
+            <Form.Item
+              name="newPassword"
+              rules={[
+                { required: true, message: '请输入新密码!' },
+                { min: 6, message: '密码至少 6 位!' },
+              ]}
+            >
+              <Input.Password prefix={<LockOutlined />} placeholder="新密码" autoComplete="new-password" autoFocus />
+            </Form.Item>
+            <Form.Item
+              name="confirmPassword"
+              dependencies={['newPassword']}
+              rules={[
+                { required: true, message: '请再次输入新密码!' },
+                ({ getFieldValue }) => ({
+                  validator(_, value) {
+                    if (!value || getFieldValue('newPassword') === value) {
+                      return Promise.resolve();
+                    }
+                    return Promise.reject(new Error('两次输入的密码不一致!'));
+                  },
+                }),
+              ]}
+            >
+              <Input.Password prefix={<LockOutlined />} placeholder="确认新密码" autoComplete="new-password" />
+            </Form.Item>
+            <Form.Item>
+              <Button type="primary" htmlType="submit" loading={loading} block>
+                修改密码并登录
+              </Button>
+            </Form.Item>
+            <Button type="link" block onClick={() => setPasswordChangeToken(null)}>
+              返回
+            </Button>
+          </Form>
+        ) : twoFactor ? (
+          <Form name="two-factor" onFinish={onTwoFactorFinish}>
+            {twoFactor.mode === 'setup' ? (
+              <div style={{ textAlign: 'center', marginBottom: 16 }}>
+                <p>您的账号需要启用双因素认证，请使用验证器应用扫描二维码：</p>
+                {setupInfo && (
+                  <>
+                    <img src={`data:image/png;base64,${setupInfo.qr_code}`} alt="二维码" width={200} height={200} />
+                    <p>无法扫码时可手动输入密钥：<Typography.Text code copyable>{setupInfo.secret}</Typography.Text></p>
+                  </>
+                )}
+              </div>
+            ) : (
+              <p>请输入验证器应用中的 6 位验证码，或使用恢复码。</p>
+            )}
+            <Form.Item
+              name="code"
+              rules={[{ required: true, message: '请输入验证码!' }]}
             >
-              登录
+              <Input
+                prefix={<SafetyOutlined />}
+                placeholder={twoFactor.mode === 'setup' ? '验证码' : '验证码或恢复码'}
+                autoComplete="one-time-code"
+                autoFocus
+              />
+            </Form.Item>
+            <Form.Item>
+              <Button type="primary" htmlType="submit" loading={loading} block>
+                {twoFactor.mode === 'setup' ? '启用并登录' : '验证'}
+              </Button>
+            </Form.Item>
+            <Button type="link" block onClick={() => { setTwoFactor(null); setSetupInfo(null); }}>
+              返回
             </Button>
-          </Form.Item>
-        </Form>
+          </Form>
+        ) : (
+          <>
+            <Form
+              name="login"
+              initialValues={{ remember: true }}
+              onFinish={onFinish}
+            >
+              <Form.Item
+                name="username"
+                rules={[{ required: true, message: '请输入用户名!' }]}
+              >
+                <Input 
+                  prefix={<UserOutlined />} 
+                  placeholder="用户名" 
+                  autoComplete="username"
+                />
+              </Form.Item>
+
+              <Form.Item
+                name="password"
+                rules={[{ required: true, message: '请输入密码!' }]}
+              >
+                <Input.Password 
+                  prefix={<LockOutlined />} 
+                  placeholder="密码" 
+                  autoComplete="current-password"
+                />
+              </Form.Item>
+
+              <Form.Item>
+                <Button 
+                  type="primary" 
+                  htmlType="submit" 
+                  loading={loading} 
+                  block
+                >
+                  登录
+                </Button>
+              </Form.Item>
+            </Form>
+            <div style={{ textAlign: 'right' }}>
+              <Link to="/forgot-password">忘记密码？</Link>
+            </div>
+            {sso?.enabled && (
+              <>
+                <Divider plain>或</Divider>
+                <Button icon={<LoginOutlined />} href={AuthService.getOIDCLoginURL()} block>
+                  {sso.name || '统一身份认证'}登录
+                </Button>
+              </>
+            )}
+          </>
+        )}
       </Card>
     </div>
   );
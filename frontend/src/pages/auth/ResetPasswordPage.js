@@ -0,0 +1,81 @@
+import React, { useState } from 'react';
+import { Form, Input, Button, Card, App } from 'antd';
+import { LockOutlined } from '@ant-design/icons';
+import { Link, useNavigate, useSearchParams } from 'react-router-dom';
+import AuthService from '../../services/authService';
+
+const ResetPasswordPage = () => {
+  const { message: messageApi } = App.useApp();
+  const [loading, setLoading] = useState(false);
+  const [searchParams] = useSearchParams();
+  const navigate = useNavigate();
+  const token = searchParams.get('token');
+
+  const onFinish = async (values) => {
+    setLoading(true);
+    try {
+      await AuthService.resetPassword(token, values.password);
+      messageApi.success('密码已重置，请使用新密码登录');
+      navigate('/login');
+    } catch (error) {
+      messageApi.error(error.response?.data?.msg || '重置密码失败');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div style={{ 
+      display: 'flex', 
+      justifyContent: 'center', 
+      alignItems: 'center', 
+      height: '100vh', 
+      background: '#f0f2f5' 
+    }}>
+      <Card title="重置密码" style={{ width: 400 }}>
+        {token ? (
+          <Form name="reset-password" onFinish={onFinish}>
+            <Form.Item
+              name="password"
+              rules={[
+                { required: true, message: '请输入新密码!' },
+                { min: 6, message: '密码至少 6 位!' }
+              ]}
+            >
+              <Input.Password prefix={<LockOutlined />} placeholder="新密码" autoComplete="new-password" />
+            </Form.Item>
+
+            <Form.Item
+              name="confirm"
+              dependencies={['password']}
+              rules={[
+                { required: true, message: '请再次输入新密码!' },
+                ({ getFieldValue }) => ({
+                  validator(_, value) {
+                    if (!value || getFieldValue('password') === value) {
+                      return Promise.resolve();
+                    }
+                    return Promise.reject(new Error('两次输入的密码不一致!'));
+                  },
+                }),
+              ]}
+            >
+              <Input.Password prefix={<LockOutlined />} placeholder="确认新密码" autoComplete="new-password" />
+            </Form.Item>
+
+            <Form.Item>
+              <Button type="primary" htmlType="submit" loading={loading} block>
+                重置密码
+              </Button>
+            </Form.Item>
+          </Form>
+        ) : (
+          <p>重置链接无效，请重新申请找回密码。</p>
+        )}
+        <Link to="/login">返回登录</Link>
+      </Card>
+    </div>
+  );
+};
+
+export default ResetPasswordPage;
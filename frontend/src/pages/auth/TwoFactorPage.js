@@ -0,0 +1,177 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import { Form, Input, Button, Card, App, Alert, Typography, Space } from 'antd';
+import { LockOutlined, SafetyOutlined } from '@ant-design/icons';
+import AuthService from '../../services/authService';
+
+const RecoveryCodes = ({ codes }) => (
+  <Alert
+    type="warning"
+    style={{ marginBottom: 16 }}
+    message="请妥善保存恢复码"
+    description={
+      <div>
+        <p>验证器不可用时，可使用恢复码登录，每个恢复码只能使用一次。离开本页后将无法再次查看。</p>
+        <Typography.Paragraph copyable={{ text: codes.join('\n') }}>
+          {codes.map((item) => <div key={item}><code>{item}</code></div>)}
+        </Typography.Paragraph>
+      </div>
+    }
+  />
+);
+
+const TwoFactorPage = () => {
+  const { message: messageApi } = App.useApp();
+  const [status, setStatus] = useState(null);
+  const [setupInfo, setSetupInfo] = useState(null);
+  const [recoveryCodes, setRecoveryCodes] = useState(null);
+  const [loading, setLoading] = useState(false);
+  const [disableForm] = Form.useForm();
+  const [regenerateForm] = Form.useForm();
+
+  const loadStatus = useCallback(async () => {
+    try {
+      const response = await AuthService.getTwoFactorStatus();
+      setStatus(response.data);
+    } catch (error) {
+      messageApi.error(error.response?.data?.msg || '获取双因素认证状态失败');
+    }
+  }, [messageApi]);
+
+  useEffect(() => {
+    loadStatus();
+  }, [loadStatus]);
+
+  const run = async (action, successMessage) => {
+    setLoading(true);
+    try {
+      await action();
+      if (successMessage) {
+        messageApi.success(successMessage);
+      }
+      await loadStatus();
+    } catch (error) {
+      messageApi.error(error.response?.data?.msg || '操作失败');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleSetup = () => run(async () => {
+    const response = await AuthService.setupTwoFactor();
+    setSetupInfo(response.data);
+    setRecoveryCodes(null);
+  });
+
+  const handleEnable = ({ code }) => run(async () => {
+    const response = await AuthService.enableTwoFactor(code);
+    setSetupInfo(null);
+    setRecoveryCodes(response.data.recovery_codes);
+  }, '已启用双因素认证');
+
+  const handleDisable = (values) => run(async () => {
+    await AuthService.disableTwoFactor(values);
+    disableForm.resetFields();
+    setRecoveryCodes(null);
+  }, '已关闭双因素认证');
+
+  const handleRegenerate = ({ code }) => run(async () => {
+    const response = await AuthService.regenerateRecoveryCodes(code);
+    regenerateForm.resetFields();
+    setRecoveryCodes(response.data.recovery_codes);
+  }, '已生成新的恢复码，旧恢复码已失效');
+
+  if (!status) {
+    return <Card title="双因素认证" loading style={{ maxWidth: 480 }} />;
+  }
+
+  return (
+    <Card title="双因素认证" style={{ maxWidth: 480 }}>
+      {recoveryCodes && <RecoveryCodes codes={recoveryCodes} />}
+
+      {!status.enabled && (
+        <>
+          <p>
+            启用后，登录时除密码外还需输入验证器应用（如 Google Authenticator、Microsoft Authenticator）生成的验证码。
+          </p>
+          {status.required && (
+            <Alert type="info" style={{ marginBottom: 16 }} message="管理员要求您的角色必须启用双因素认证" />
+          )}
+          {setupInfo ? (
+            <Form name="enable-two-factor" layout="vertical" onFinish={handleEnable}>
+              <div style={{ textAlign: 'center', marginBottom: 16 }}>
+                <img src={`data:image/png;base64,${setupInfo.qr_code}`} alt="二维码" width={200} height={200} />
+                <p>无法扫码时可手动输入密钥：<Typography.Text code copyable>{setupInfo.secret}</Typography.Text></p>
+              </div>
+              <Form.Item
+                name="code"
+                label="验证码"
+                rules={[{ required: true, message: '请输入验证码!' }]}
+              >
+                <Input prefix={<SafetyOutlined />} autoComplete="one-time-code" />
+              </Form.Item>
+              <Form.Item>
+                <Button type="primary" htmlType="submit" loading={loading}>
+                  启用
+                </Button>
+              </Form.Item>
+            </Form>
+          ) : (
+            <Button type="primary" onClick={handleSetup} loading={loading}>
+              开始设置
+            </Button>
+          )}
+        </>
+      )}
+
+      {status.enabled && (
+        <Space direction="vertical" style={{ width: '100%' }}>
+          <Alert
+            type="success"
+            message="已启用双因素认证"
+            description={`剩余可用恢复码：${status.remaining_recovery_codes} 个`}
+          />
+
+          <Form form={regenerateForm} name="regenerate-recovery-codes" layout="vertical" onFinish={handleRegenerate}>
+            <Form.Item
+              name="code"
+              label="重新生成恢复码"
+              rules={[{ required: true, message: '请输入验证码!' }]}
+            >
+              <Input prefix={<SafetyOutlined />} placeholder="验证码" autoComplete="one-time-code" />
+            </Form.Item>
+            <Form.Item>
+              <Button htmlType="submit" loading={loading}>
+                重新生成
+              </Button>
+            </Form.Item>
+          </Form>
+
+          {!status.required && (
+            <Form form={disableForm} name="disable-two-factor" layout="vertical" onFinish={handleDisable}>
+              <Form.Item
+                name="password"
+                label="关闭双因素认证"
+                rules={[{ required: true, message: '请输入当前密码!' }]}
+              >
+                <Input.Password prefix={<LockOutlined />} placeholder="当前密码" autoComplete="current-password" />
+              </Form.Item>
+              <Form.Item
+                name="code"
+                rules={[{ required: true, message: '请输入验证码!' }]}
+              >
+                <Input prefix={<SafetyOutlined />} placeholder="验证码或恢复码" autoComplete="one-time-code" />
+              </Form.Item>
+              <Form.Item>
+                <Button danger htmlType="submit" loading={loading}>
+                  关闭
+                </Button>
+              </Form.Item>
+            </Form>
+          )}
+        </Space>
+      )}
+    </Card>
+  );
+};
+
+export default TwoFactorPage;
@@ -1,4 +1,4 @@
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useRef } from 'react';
 import { 
   Table, 
   Button, 
@@ -9,7 +9,8 @@ import {
   Select, 
   Space,
   Popconfirm,
-  Alert
+  Alert,
+  Input
 } from 'antd';
 import { PlusOutlined, DeleteOutlined, RedoOutlined } from '@ant-design/icons';
 import EnrollmentService from '../../services/enrollmentService';
@@ -26,15 +27,26 @@ const EnrollmentPage = () => {
   const [isModalVisible, setIsModalVisible] = useState(false);
   const [form] = Form.useForm();
   const [enrollmentError, setEnrollmentError] = useState(null); // 添加选课错误状态
+  // 服务端分页和筛选
+  const [pagination, setPagination] = useState({ current: 1, pageSize: 10, total: 0 });
+  const [query, setQuery] = useState({ courseId: undefined, keyword: '' });
+  const studentSearchTimer = useRef(null);
 
   // 获取选课记录
-  const fetchEnrollments = async () => {
+  const fetchEnrollments = async (page = pagination.current, pageSize = pagination.pageSize, params = query) => {
     setLoading(true);
     try {
-      const response = await EnrollmentService.getEnrollments();
+      const response = await EnrollmentService.getEnrollments({
+        page,
+        page_size: pageSize,
+        sort: '-enroll_time',
+        course_id: params.courseId,
+        keyword: params.keyword || undefined,
+      });
       // 从响应中正确提取数据
-      const data = response.data?.data || [];
-      setEnrollments(Array.isArray(data) ? data : []);
+      const result = response.data?.data || {};
+      setEnrollments(Array.isArray(result.items) ? result.items : []);
+      setPagination({ current: page, pageSize, total: result.total || 0 });
       setLoading(false);
     } catch (error) {
       console.error('获取选课记录失败:', error);
@@ -44,12 +56,12 @@ const EnrollmentPage = () => {
     }
   };
 
-  // 获取学生列表
-  const fetchStudents = async () => {
+  // 按学号或姓名搜索学生，学生数量较多，只取前 50 条
+  const fetchStudents = async (keyword = '') => {
     try {
-      const response = await UserService.getStudents();
+      const response = await UserService.getStudents({ page_size: 50, keyword: keyword || undefined });
       // 从响应中正确提取数据
-      const data = response.data?.data || [];
+      const data = response.data?.data?.items || [];
       // 确保数据是数组格式
       const studentList = Array.isArray(data) ? data : [];
       setStudents(studentList);
@@ -77,13 +89,24 @@ const EnrollmentPage = () => {
   };
 
   useEffect(() => {
-    fetchEnrollments();
-    fetchStudents();
     fetchCourses();
+    return () => clearTimeout(studentSearchTimer.current);
   }, []);
 
+  // 筛选条件变化时回到第一页
+  useEffect(() => {
+    fetchEnrollments(1);
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [query]);
+
+  const handleStudentSearch = (value) => {
+    clearTimeout(studentSearchTimer.current);
+    studentSearchTimer.current = setTimeout(() => fetchStudents(value), 300);
+  };
+
   const showAddModal = () => {
     form.resetFields();
+    fetchStudents();
     setEnrollmentError(null); // 清除之前的选课错误
     setIsModalVisible(true);
   };
@@ -135,11 +158,7 @@ const EnrollmentPage = () => {
       title: '学生学号',
       dataIndex: 'studentId',
       key: 'studentId',
-      render: (_, record) => {
-        // 使用正确的字段名查找对应的学生信息
-        const student = students.find(s => s.ID === record.studentId);
-        return student ? student.Username : record.studentId;
-      }
+      render: (_, record) => record.studentUsername || record.studentId
     },
     {
       title: '学生姓名',
@@ -212,12 +231,36 @@ const EnrollmentPage = () => {
           </Button>
         }
       >
+        <Space style={{ marginBottom: 16 }}>
+          <Input.Search
+            placeholder="搜索学号、学生姓名或课程名称"
+            allowClear
+            onSearch={(keyword) => setQuery({ ...query, keyword })}
+            style={{ width: 280 }}
+          />
+          <Select
+            placeholder="全部课程"
+            allowClear
+            showSearch
+            optionFilterProp="children"
+            value={query.courseId}
+            onChange={(courseId) => setQuery({ ...query, courseId })}
+            style={{ width: 200 }}
+          >
+            {courses.map(course => (
+              <Option key={course.id} value={course.id}>
+                {course.name}
+              </Option>
+            ))}
+          </Select>
+        </Space>
         <Table 
           dataSource={enrollments} 
           columns={columns} 
           rowKey="id" 
           loading={loading}
-          pagination={{ pageSize: 10 }}
+          pagination={{ ...pagination, showSizeChanger: true, showTotal: (total) => `共 ${total} 条` }}
+          onChange={(pag) => fetchEnrollments(pag.current, pag.pageSize)}
         />
       </Card>
 
@@ -248,11 +291,9 @@ const EnrollmentPage = () => {
           >
             <Select 
               showSearch
-              placeholder="请选择学生"
-              optionFilterProp="children"
-              filterOption={(input, option) =>
-                option.children.toLowerCase().indexOf(input.toLowerCase()) >= 0
-              }
+              placeholder="输入学号或姓名搜索学生"
+              filterOption={false}
+              onSearch={handleStudentSearch}
             >
               {students.map(student => (
                 <Option key={student.ID} value={student.ID}>
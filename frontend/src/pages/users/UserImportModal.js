@@ -0,0 +1,194 @@
+import React, { useEffect, useRef, useState } from 'react';
+import { Modal, Upload, Button, Alert, Table, Progress, Space, Statistic, Row, Col, Checkbox, message } from 'antd';
+import { InboxOutlined, DownloadOutlined } from '@ant-design/icons';
+import UserService from '../../services/userService';
+
+const POLL_INTERVAL = 2000;
+
+const errorColumns = [
+  { title: '行号', dataIndex: 'row', key: 'row', width: 70 },
+  { title: '用户名', dataIndex: 'username', key: 'username', width: 120 },
+  { title: '姓名', dataIndex: 'name', key: 'name', width: 100 },
+  {
+    title: '问题',
+    dataIndex: 'errors',
+    key: 'errors',
+    render: (errors) => (errors || []).join('；'),
+  },
+];
+
+// 批量导入用户：上传文件预检 → 确认导入 → 显示进度 → 下载结果（含系统生成的初始密码）
+const UserImportModal = ({ open, onClose, onImported }) => {
+  const [job, setJob] = useState(null);
+  const [uploading, setUploading] = useState(false);
+  const [committing, setCommitting] = useState(false);
+  // 忽略文件中的密码，为每个账号生成随机初始密码
+  const [generatePasswords, setGeneratePasswords] = useState(true);
+  const pollTimer = useRef(null);
+
+  const stopPolling = () => {
+    if (pollTimer.current) {
+      clearInterval(pollTimer.current);
+      pollTimer.current = null;
+    }
+  };
+
+  useEffect(() => stopPolling, []);
+
+  const handleClose = () => {
+    stopPolling();
+    setJob(null);
+    onClose();
+  };
+
+  const handleUpload = async (file) => {
+    setUploading(true);
+    try {
+      const response = await UserService.uploadImport(file, generatePasswords);
+      setJob(response.data.data);
+    } catch (error) {
+      message.error(error.response?.data?.msg || '上传失败');
+    } finally {
+      setUploading(false);
+    }
+    return false;
+  };
+
+  const handleCommit = async () => {
+    setCommitting(true);
+    try {
+      const response = await UserService.commitImport(job.id);
+      setJob(response.data.data);
+      pollTimer.current = setInterval(async () => {
+        try {
+          const progress = await UserService.getImport(job.id);
+          const data = progress.data.data;
+          setJob(data);
+          if (data.status === 'completed') {
+            stopPolling();
+            message.success(`导入完成：创建 ${data.created} 个用户`);
+            onImported();
+          }
+        } catch (error) {
+          stopPolling();
+          message.error(error.response?.data?.msg || '获取导入进度失败');
+        }
+      }, POLL_INTERVAL);
+    } catch (error) {
+      message.error(error.response?.data?.msg || '导入失败');
+    } finally {
+      setCommitting(false);
+    }
+  };
+
+  const handleDownload = async () => {
+    try {
+      const response = await UserService.downloadImportResult(job.id);
+      const url = window.URL.createObjectURL(response.data);
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = `user_import_${job.id}.xlsx`;
+      link.click();
+      window.URL.revokeObjectURL(url);
+    } catch (error) {
+      message.error('下载导入结果失败');
+    }
+  };
+
+  const completed = job?.status === 'completed';
+  const running = job?.status === 'running';
+
+  return (
+    <Modal
+      title="批量导入用户"
+      open={open}
+      onCancel={handleClose}
+      width={760}
+      destroyOnClose
+      maskClosable={!running}
+      footer={
+        <Space>
+          {job && (
+            <Button icon={<DownloadOutlined />} onClick={handleDownload}>
+              下载{completed ? '导入结果' : '预检报告'}
+            </Button>
+          )}
+          {job?.status === 'validated' && (
+            <Button type="primary" onClick={handleCommit} loading={committing} disabled={job.valid === 0}>
+              导入 {job.valid} 个用户
+            </Button>
+          )}
+          <Button onClick={handleClose}>{completed ? '完成' : '关闭'}</Button>
+        </Space>
+      }
+    >
+      {!job && (
+        <>
+          <Alert
+            type="info"
+            style={{ marginBottom: 16 }}
+            message="支持 .csv 和 .xlsx 文件，第一行为表头"
+            description="列：用户名（学号/工号）、姓名、角色（student/assistant/teacher，默认 student）、邮箱、初始密码。未填写初始密码时由系统生成，导入完成后在结果文件中查看。导入的账号首次登录时需要修改密码。"
+          />
+          <Checkbox
+            checked={generatePasswords}
+            onChange={(e) => setGeneratePasswords(e.target.checked)}
+            style={{ marginBottom: 16 }}
+          >
+            为所有账号生成随机初始密码（忽略文件中的初始密码列）
+          </Checkbox>
+          <Upload.Dragger
+            accept=".csv,.xlsx"
+            showUploadList={false}
+            beforeUpload={handleUpload}
+            disabled={uploading}
+          >
+            <p className="ant-upload-drag-icon">
+              <InboxOutlined />
+            </p>
+            <p className="ant-upload-text">{uploading ? '正在预检…' : '点击或拖拽文件到此处上传'}</p>
+          </Upload.Dragger>
+        </>
+      )}
+
+      {job && (
+        <>
+          <Row gutter={16} style={{ marginBottom: 16 }}>
+            <Col span={6}><Statistic title="总行数" value={job.total} /></Col>
+            <Col span={6}><Statistic title="预检通过" value={job.valid} /></Col>
+            <Col span={6}><Statistic title="预检不通过" value={job.invalid} /></Col>
+            <Col span={6}><Statistic title="已创建" value={job.created} /></Col>
+          </Row>
+
+          {(running || completed) && (
+            <Progress
+              percent={job.valid ? Math.floor((job.processed / job.valid) * 100) : 100}
+              status={completed ? 'success' : 'active'}
+              style={{ marginBottom: 16 }}
+            />
+          )}
+
+          {completed && job.generated_passwords > 0 && (
+            <Alert
+              type="warning"
+              style={{ marginBottom: 16 }}
+              message="导入结果中包含系统生成的初始密码，24 小时内可重复下载，请及时下载并妥善保存"
+            />
+          )}
+
+          {job.errors.length > 0 && (
+            <Table
+              size="small"
+              dataSource={job.errors}
+              columns={errorColumns}
+              rowKey="row"
+              pagination={{ pageSize: 8 }}
+            />
+          )}
+        </>
+      )}
+    </Modal>
+  );
+};
+
+export default UserImportModal;
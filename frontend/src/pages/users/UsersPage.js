@@ -12,11 +12,14 @@ import {
   Space,
   Popconfirm,
   Result,
-  Alert
+  Alert,
+  Checkbox,
+  Typography
 } from 'antd';
-import { PlusOutlined, EditOutlined, DeleteOutlined, KeyOutlined, RedoOutlined } from '@ant-design/icons';
+import { PlusOutlined, EditOutlined, DeleteOutlined, KeyOutlined, RedoOutlined, UploadOutlined } from '@ant-design/icons';
 import UserService from '../../services/userService';
 import AuthService from '../../services/authService';
+import UserImportModal from './UserImportModal';
 
 const { Option } = Select;
 
@@ -31,6 +34,8 @@ const transformUserData = (user) => {
     name: user.Name || user.name,
     role: user.Role || user.role,
     email: user.Email || user.email,
+    authSource: user.AuthSource || user.authSource || 'local',
+    mustChangePassword: user.MustChangePassword ?? user.mustChangePassword ?? false,
     createdAt: user.CreatedAt || user.createdAt,
     updatedAt: user.UpdatedAt || user.updatedAt
   };
@@ -43,20 +48,30 @@ const UsersPage = () => {
   const [editingUser, setEditingUser] = useState(null);
   const [isPasswordModalVisible, setIsPasswordModalVisible] = useState(false);
   const [selectedUser, setSelectedUser] = useState(null);
+  const [isImportModalVisible, setIsImportModalVisible] = useState(false);
   const [form] = Form.useForm();
   const [passwordForm] = Form.useForm();
   const [usernameError, setUsernameError] = useState(null);
+  // 服务端分页、排序和筛选
+  const [pagination, setPagination] = useState({ current: 1, pageSize: 10, total: 0 });
+  const [query, setQuery] = useState({ role: undefined, keyword: '', sort: undefined });
   
   // 获取用户列表
-  const fetchUsers = async () => {
+  const fetchUsers = async (page = pagination.current, pageSize = pagination.pageSize, params = query) => {
     setLoading(true);
     try {
-      const response = await UserService.getUsers();
-      // 提取数据，兼容不同响应结构
-      const userData = response.data?.data || response.data || [];
-      // 确保 userData 是数组，然后转换数据格式以匹配前端期望的字段名
-      const transformedUsers = Array.isArray(userData) ? userData.map(transformUserData) : [];
+      const response = await UserService.getUsers({
+        page,
+        page_size: pageSize,
+        sort: params.sort,
+        role: params.role,
+        keyword: params.keyword || undefined,
+      });
+      const result = response.data?.data || {};
+      // 转换数据格式以匹配前端期望的字段名
+      const transformedUsers = Array.isArray(result.items) ? result.items.map(transformUserData) : [];
       setUsers(transformedUsers);
+      setPagination({ current: page, pageSize, total: result.total || 0 });
     } catch (error) {
       console.error('获取用户列表失败:', error);
       message.error(error.message || '获取用户列表失败');
@@ -65,9 +80,20 @@ const UsersPage = () => {
     }
   };
 
+  // 筛选或排序条件变化时回到第一页
   useEffect(() => {
-    fetchUsers();
-  }, []);
+    fetchUsers(1);
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [query]);
+
+  const handleTableChange = (pag, _filters, sorter) => {
+    const sort = sorter.order ? `${sorter.order === 'descend' ? '-' : ''}${sorter.field}` : undefined;
+    if (sort !== query.sort) {
+      setQuery({ ...query, sort });
+      return;
+    }
+    fetchUsers(pag.current, pag.pageSize);
+  };
 
   // 生成随机用户名
   const generateRandomUsername = (name = '') => {
@@ -91,6 +117,21 @@ const UsersPage = () => {
     setIsModalVisible(true);
   };
 
+  // 系统生成的初始密码只返回一次，展示给管理员转交用户
+  const showInitialPassword = (user, password) => {
+    Modal.info({
+      title: '初始密码已生成',
+      content: (
+        <div>
+          <p>【{user.name}】的初始密码如下，关闭后无法再次查看，请及时转交。用户首次登录时需要修改密码。</p>
+          <Typography.Paragraph copyable={{ text: password }}>
+            <code>{password}</code>
+          </Typography.Paragraph>
+        </div>
+      ),
+    });
+  };
+
   const showPasswordModal = (user) => {
     const transformedUser = transformUserData(user);
     setSelectedUser(transformedUser);
@@ -113,6 +154,9 @@ const UsersPage = () => {
           const response = await UserService.createUser(values);
           message.success(response.data?.msg || response.msg || '用户添加成功');
           setIsModalVisible(false);
+          if (response.data?.data?.initial_password) {
+            showInitialPassword(values, response.data.data.initial_password);
+          }
           setUsernameError(null); // 清除错误
         } catch (error) {
           // 检查是否是用户名已存在的错误
@@ -149,9 +193,13 @@ const UsersPage = () => {
   const handlePasswordReset = async () => {
     try {
       const values = await passwordForm.validateFields();
-      const response = await UserService.resetPassword(selectedUser.id, values.password);
+      const response = await UserService.resetPassword(selectedUser.id, values.password, values.generate);
       message.success(response.data?.msg || response.msg || '密码重置成功');
       setIsPasswordModalVisible(false);
+      if (response.data?.data?.initial_password) {
+        showInitialPassword(selectedUser, response.data.data.initial_password);
+      }
+      fetchUsers();
     } catch (error) {
       console.error('密码重置失败:', error);
       message.error(error.response?.data?.msg || error.message || '密码重置失败');
@@ -185,16 +233,19 @@ const UsersPage = () => {
       title: '用户名',
       dataIndex: 'username',
       key: 'username',
+      sorter: true,
     },
     {
       title: '姓名',
       dataIndex: 'name',
       key: 'name',
+      sorter: true,
     },
     {
       title: '角色',
       dataIndex: 'role',
       key: 'role',
+      sorter: true,
       render: (role) => getRoleTag(role),
     },
     {
@@ -202,6 +253,17 @@ const UsersPage = () => {
       dataIndex: 'email',
       key: 'email',
     },
+    {
+      title: '账号来源',
+      dataIndex: 'authSource',
+      key: 'authSource',
+      render: (source, record) => (
+        <>
+          {source === 'local' ? <Tag>本地</Tag> : <Tag color="purple">统一身份认证</Tag>}
+          {record.mustChangePassword && <Tag color="orange">待改密</Tag>}
+        </>
+      ),
+    },
     {
       title: '操作',
       key: 'action',
@@ -219,6 +281,7 @@ const UsersPage = () => {
             icon={<KeyOutlined />} 
             onClick={() => showPasswordModal(record)}
             size="small"
+            disabled={record.authSource !== 'local'}
           >
             重置密码
           </Button>
@@ -247,21 +310,50 @@ const UsersPage = () => {
       <Card 
         title="用户管理" 
         extra={
-          <Button 
-            type="primary" 
-            icon={<PlusOutlined />} 
-            onClick={showAddModal}
-          >
-            添加用户
-          </Button>
+          <Space>
+            <Button 
+              icon={<UploadOutlined />} 
+              onClick={() => setIsImportModalVisible(true)}
+            >
+              批量导入
+            </Button>
+            <Button 
+              type="primary" 
+              icon={<PlusOutlined />} 
+              onClick={showAddModal}
+            >
+              添加用户
+            </Button>
+          </Space>
         }
       >
+        <Space style={{ marginBottom: 16 }}>
+          <Input.Search
+            placeholder="搜索用户名、姓名或邮箱"
+            allowClear
+            onSearch={(keyword) => setQuery({ ...query, keyword })}
+            style={{ width: 260 }}
+          />
+          <Select
+            placeholder="全部角色"
+            allowClear
+            value={query.role}
+            onChange={(role) => setQuery({ ...query, role })}
+            style={{ width: 140 }}
+          >
+            <Option value="admin">管理员</Option>
+            <Option value="teacher">教师</Option>
+            <Option value="assistant">助教</Option>
+            <Option value="student">学生</Option>
+          </Select>
+        </Space>
         <Table 
           dataSource={users} 
           columns={columns} 
           rowKey="id" 
           loading={loading}
-          pagination={{ pageSize: 10 }}
+          pagination={{ ...pagination, showSizeChanger: true, showTotal: (total) => `共 ${total} 条` }}
+          onChange={handleTableChange}
         />
       </Card>
 
@@ -342,20 +434,36 @@ const UsersPage = () => {
           </Form.Item>
           
           {!editingUser && (
-            <Form.Item
-              name="password"
-              label="密码"
-              rules={[
-                { required: true, message: '请输入密码!' },
-                { min: 6, message: '密码至少6位!' }
-              ]}
-            >
-              <Input.Password />
-            </Form.Item>
+            <>
+              <Form.Item name="generate_password" valuePropName="checked">
+                <Checkbox>自动生成随机初始密码</Checkbox>
+              </Form.Item>
+              <Form.Item noStyle shouldUpdate={(prev, cur) => prev.generate_password !== cur.generate_password}>
+                {({ getFieldValue }) => !getFieldValue('generate_password') && (
+                  <Form.Item
+                    name="password"
+                    label="初始密码"
+                    extra="用户首次登录时需要修改密码"
+                    rules={[
+                      { required: true, message: '请输入密码!' },
+                      { min: 6, message: '密码至少6位!' }
+                    ]}
+                  >
+                    <Input.Password />
+                  </Form.Item>
+                )}
+              </Form.Item>
+            </>
           )}
         </Form>
       </Modal>
 
+      <UserImportModal
+        open={isImportModalVisible}
+        onClose={() => setIsImportModalVisible(false)}
+        onImported={fetchUsers}
+      />
+
       <Modal
         title={`重置【${selectedUser?.name}】的密码`}
         open={isPasswordModalVisible}
@@ -367,15 +475,23 @@ const UsersPage = () => {
           form={passwordForm}
           layout="vertical"
         >
-          <Form.Item
-            name="password"
-            label="新密码"
-            rules={[
-              { required: true, message: '请输入新密码!' },
-              { min: 6, message: '密码至少6位!' }
-            ]}
-          >
-            <Input.Password />
+          <Form.Item name="generate" valuePropName="checked">
+            <Checkbox>自动生成随机密码</Checkbox>
+          </Form.Item>
+          <Form.Item noStyle shouldUpdate={(prev, cur) => prev.generate !== cur.generate}>
+            {({ getFieldValue }) => !getFieldValue('generate') && (
+              <Form.Item
+                name="password"
+                label="新密码"
+                extra="用户下次登录时需要修改密码"
+                rules={[
+                  { required: true, message: '请输入新密码!' },
+                  { min: 6, message: '密码至少6位!' }
+                ]}
+              >
+                <Input.Password />
+              </Form.Item>
+            )}
           </Form.Item>
         </Form>
       </Modal>
@@ -2,6 +2,10 @@ import React from 'react';
 import { Routes, Route, Navigate } from 'react-router-dom';
 import { Card, Typography, App as AntApp } from 'antd';
 import LoginPage from '../pages/auth/LoginPage';
+import ForgotPasswordPage from '../pages/auth/ForgotPasswordPage';
+import ResetPasswordPage from '../pages/auth/ResetPasswordPage';
+import ChangePasswordPage from '../pages/auth/ChangePasswordPage';
+import TwoFactorPage from '../pages/auth/TwoFactorPage';
 import MainLayout from '../components/layout/MainLayout';
 import AuthService from '../services/authService';
 import AttendancePage from '../pages/attendance/AttendancePage';
@@ -76,6 +80,15 @@ const AppRoutes = () => {
             </AuthRoute>
           } 
         />
+        <Route 
+          path="/forgot-password" 
+          element={
+            <AuthRoute>
+              <ForgotPasswordPage />
+            </AuthRoute>
+          } 
+        />
+        <Route path="/reset-password" element={<ResetPasswordPage />} />
         <Route 
           path="/" 
           element={
@@ -90,6 +103,8 @@ const AppRoutes = () => {
           <Route path="/courses" element={<CoursesPage />} />
           <Route path="/enrollments" element={<EnrollmentPage />} />
           <Route path="/attendance" element={<AttendancePage />} />
+          <Route path="/change-password" element={<ChangePasswordPage />} />
+          <Route path="/two-factor" element={<TwoFactorPage />} />
         </Route>
       </Routes>
     </AntApp>
@@ -1,7 +1,7 @@
 import axios from 'axios';
 
 // API基础配置
-const API_BASE = 'http://localhost:8080/api';
+export const API_BASE = 'http://localhost:8080/api';
 
 // 创建axios实例
 const apiClient = axios.create({
@@ -27,27 +27,68 @@ apiClient.interceptors.request.use(
   }
 );
 
+// 清除登录状态并跳转到登录页
+const redirectToLogin = () => {
+  localStorage.removeItem('authUser');
+  localStorage.removeItem('authToken');
+  localStorage.removeItem('refreshToken');
+  // 只有在浏览器环境中才进行重定向
+  if (typeof window !== 'undefined') {
+    window.location.href = '/login';
+  }
+};
+
+// 正在进行的刷新请求，多个请求同时 401 时共用一次刷新
+let refreshPromise = null;
+
+const refreshAccessToken = () => {
+  if (!refreshPromise) {
+    const refreshToken = localStorage.getItem('refreshToken');
+    refreshPromise = axios
+      .post(`${API_BASE}/refresh`, { refresh_token: refreshToken }, { withCredentials: true })
+      .then((res) => {
+        const data = res.data?.data || {};
+        localStorage.setItem('authToken', data.token);
+        localStorage.setItem('refreshToken', data.refresh_token);
+        return data.token;
+      })
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
 // 响应拦截器
 apiClient.interceptors.response.use(
   (response) => {
     return response;
   },
-  (error) => {
-    // 检查是否是登录请求
+  async (error) => {
+    // 检查是否是登录请求（包括双因素认证的第二步和单点登录）
     const isLoginRequest = error.config && 
       (error.config.url === '/login' || 
        error.config.url === 'login' ||
-       error.config.url.endsWith('/login'));
+       error.config.url.endsWith('/login') ||
+       error.config.url.startsWith('/login/') ||
+       error.config.url.startsWith('/oidc/'));
     
-    // 只有在非登录请求且状态码为401时才重定向到登录页
+    // 只有在非登录请求且状态码为401时才处理
     if (error.response?.status === 401 && !isLoginRequest) {
-      // 未授权，清除用户信息并跳转到登录页
-      localStorage.removeItem('authUser');
-      localStorage.removeItem('authToken');
-      // 只有在浏览器环境中才进行重定向
-      if (typeof window !== 'undefined') {
-        window.location.href = '/login';
+      // 访问令牌过期时先尝试用刷新令牌换取新令牌，每个请求只重试一次
+      if (localStorage.getItem('refreshToken') && !error.config._retried) {
+        try {
+          const token = await refreshAccessToken();
+          error.config._retried = true;
+          error.config.headers.Authorization = `Bearer ${token}`;
+          return apiClient(error.config);
+        } catch (refreshError) {
+          redirectToLogin();
+          return Promise.reject(refreshError);
+        }
       }
+      // 未授权，清除用户信息并跳转到登录页
+      redirectToLogin();
     }
     return Promise.reject(error);
   }
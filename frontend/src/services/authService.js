@@ -1,4 +1,4 @@
-import apiClient from './api';
+import apiClient, { API_BASE } from './api';
 
 class AuthService {
   /**
@@ -58,8 +58,173 @@ class AuthService {
    * 用户登出
    */
   static logout() {
+    // 通知后端吊销刷新令牌，失败不影响本地退出
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (refreshToken) {
+      apiClient.post('/logout', { refresh_token: refreshToken }).catch(() => {});
+    }
     localStorage.removeItem('authUser');
     localStorage.removeItem('authToken');
+    localStorage.removeItem('refreshToken');
+  }
+
+  /**
+   * 修改当前用户密码，成功后保存新的token
+   * @param {Object} data - { current_password, new_password }
+   * @returns {Promise} 修改结果
+   */
+  static async changePassword(data) {
+    const response = await apiClient.post('/change-password', data);
+    const result = response.data?.data;
+    if (result?.token) {
+      localStorage.setItem('authToken', result.token);
+      localStorage.setItem('refreshToken', result.refresh_token);
+    }
+    return response.data;
+  }
+
+  /**
+   * 申请找回密码，重置链接发送到账号绑定的邮箱
+   * @param {string} username - 用户名或邮箱
+   * @returns {Promise} 申请结果
+   */
+  static async forgotPassword(username) {
+    const response = await apiClient.post('/forgot-password', { username });
+    return response.data;
+  }
+
+  /**
+   * 使用邮件中的重置令牌设置新密码
+   * @param {string} token - 重置令牌
+   * @param {string} password - 新密码
+   * @returns {Promise} 重置结果
+   */
+  static async resetPassword(token, password) {
+    const response = await apiClient.post('/reset-password', { token, password });
+    return response.data;
+  }
+
+  /**
+   * 登录第二步：提交验证器中的验证码或恢复码
+   * @param {string} challengeToken - 登录第一步返回的令牌
+   * @param {string} code - 验证码或恢复码
+   * @returns {Promise} 登录结果
+   */
+  static async loginTwoFactor(challengeToken, code) {
+    const response = await apiClient.post('/login/2fa', { challenge_token: challengeToken, code });
+    return response.data;
+  }
+
+  /**
+   * 角色要求启用双因素认证时，在登录过程中获取绑定二维码
+   * @param {string} challengeToken - 登录第一步返回的令牌
+   * @returns {Promise} { secret, url, qr_code }
+   */
+  static async loginTwoFactorSetup(challengeToken) {
+    const response = await apiClient.post('/login/2fa/setup', { challenge_token: challengeToken });
+    return response.data;
+  }
+
+  /**
+   * 在登录过程中确认绑定并完成登录
+   * @param {string} challengeToken - 登录第一步返回的令牌
+   * @param {string} code - 验证码
+   * @returns {Promise} 登录结果（包含恢复码）
+   */
+  static async loginTwoFactorEnable(challengeToken, code) {
+    const response = await apiClient.post('/login/2fa/enable', { challenge_token: challengeToken, code });
+    return response.data;
+  }
+
+  /**
+   * 首次登录或管理员重置密码后，使用登录返回的专用令牌设置新密码
+   * @param {string} token - 登录返回的 password_change_token
+   * @param {string} newPassword - 新密码
+   * @returns {Promise} 登录结果
+   */
+  static async completeRequiredPasswordChange(token, newPassword) {
+    const response = await apiClient.post('/login/change-password', { token, new_password: newPassword });
+    return response.data;
+  }
+
+  /**
+   * 获取单点登录配置
+   * @returns {Promise} { enabled, name }
+   */
+  static async getOIDCConfig() {
+    const response = await apiClient.get('/oidc/config');
+    return response.data;
+  }
+
+  /**
+   * 单点登录入口地址，由后端跳转到身份提供方
+   * @returns {string} 登录地址
+   */
+  static getOIDCLoginURL() {
+    return `${API_BASE}/oidc/login`;
+  }
+
+  /**
+   * 使用单点登录回调得到的一次性换取码获取令牌
+   * @param {string} code - 换取码
+   * @returns {Promise} 登录结果
+   */
+  static async exchangeOIDCCode(code) {
+    const response = await apiClient.post('/oidc/exchange', { code });
+    return response.data;
+  }
+
+  /**
+   * 获取当前用户的双因素认证状态
+   * @returns {Promise} { enabled, required, remaining_recovery_codes }
+   */
+  static async getTwoFactorStatus() {
+    const response = await apiClient.get('/2fa');
+    return response.data;
+  }
+
+  /**
+   * 生成新的双因素认证密钥和绑定二维码
+   * @returns {Promise} { secret, url, qr_code }
+   */
+  static async setupTwoFactor() {
+    const response = await apiClient.post('/2fa/setup');
+    return response.data;
+  }
+
+  /**
+   * 确认绑定并启用双因素认证
+   * @param {string} code - 验证码
+   * @returns {Promise} { recovery_codes }
+   */
+  static async enableTwoFactor(code) {
+    const response = await apiClient.post('/2fa/enable', { code });
+    return response.data;
+  }
+
+  /**
+   * 关闭双因素认证，成功后保存新的token
+   * @param {Object} data - { password, code }
+   * @returns {Promise} 关闭结果
+   */
+  static async disableTwoFactor(data) {
+    const response = await apiClient.post('/2fa/disable', data);
+    const result = response.data?.data;
+    if (result?.token) {
+      localStorage.setItem('authToken', result.token);
+      localStorage.setItem('refreshToken', result.refresh_token);
+    }
+    return response.data;
+  }
+
+  /**
+   * 重新生成恢复码
+   * @param {string} code - 验证码
+   * @returns {Promise} { recovery_codes }
+   */
+  static async regenerateRecoveryCodes(code) {
+    const response = await apiClient.post('/2fa/recovery-codes', { code });
+    return response.data;
   }
 
   /**
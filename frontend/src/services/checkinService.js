@@ -4,8 +4,8 @@ const CheckinService = {
   // 发起签到
   startCheckin: (data) => apiClient.post('/start-checkin', data),
   
-  // 获取签到会话列表
-  getCheckinSessions: () => apiClient.get('/checkin-sessions'),
+  // 获取签到会话列表，params 支持 page、page_size、sort、course_id、status、keyword 等
+  getCheckinSessions: (params) => apiClient.get('/checkin-sessions', { params }),
   
   // 获取签到记录
   getCheckinRecords: (sessionId) => apiClient.get(`/records/${sessionId}`),
@@ -1,10 +1,10 @@
 import apiClient from './api';
 
 class EnrollmentService {
-  // 获取所有选课记录
-  async getEnrollments() {
+  // 获取选课记录，params 支持 page、page_size、sort、course_id、semester、keyword 等
+  async getEnrollments(params) {
     try {
-      const response = await apiClient.get('/enrollments');
+      const response = await apiClient.get('/enrollments', { params });
       return response;
     } catch (error) {
       throw new Error(error.response?.data?.msg || '获取选课记录失败');
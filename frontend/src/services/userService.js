@@ -1,14 +1,14 @@
 import apiClient from './api';
 
 class UserService {
-  // 获取所有用户
-  getUsers() {
-    return apiClient.get('/users');
+  // 获取用户列表，params 支持 page、page_size、sort、role、keyword 等，分页时返回 { items, total }
+  getUsers(params) {
+    return apiClient.get('/users', { params });
   }
 
-  // 获取所有学生
-  getStudents() {
-    return apiClient.get('/students');
+  // 获取学生列表，params 支持 page、page_size、sort、course_id、keyword 等
+  getStudents(params) {
+    return apiClient.get('/students', { params });
   }
 
   // 获取所有教师
@@ -31,9 +31,37 @@ class UserService {
     return apiClient.delete(`/users/${id}`);
   }
 
-  // 重置用户密码
-  resetPassword(id, password) {
-    return apiClient.put(`/users/${id}/password`, { password });
+  // 重置用户密码，generate 为 true 时由系统生成随机密码
+  resetPassword(id, password, generate = false) {
+    return apiClient.put(`/users/${id}/password`, { password, generate });
+  }
+
+  // 上传导入文件并预检，generatePasswords 为 true 时忽略文件中的密码，全部随机生成
+  uploadImport(file, generatePasswords = false) {
+    const formData = new FormData();
+    formData.append('file', file);
+    formData.append('generate_passwords', generatePasswords ? 'true' : 'false');
+    return apiClient.post('/user-imports', formData, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+  }
+
+  // 确认导入
+  commitImport(id) {
+    return apiClient.post(`/user-imports/${id}/commit`);
+  }
+
+  // 查询导入进度
+  getImport(id) {
+    return apiClient.get(`/user-imports/${id}`);
+  }
+
+  // 下载导入结果
+  downloadImportResult(id, format = 'xlsx') {
+    return apiClient.get(`/user-imports/${id}/result`, {
+      params: { format },
+      responseType: 'blob',
+    });
   }
 }
 